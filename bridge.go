@@ -0,0 +1,61 @@
+package cond
+
+import "context"
+
+// BridgeOption configures [commonCond.Bridge].
+type BridgeOption func(*bridgeOptions)
+
+type bridgeOptions struct {
+	drop bool
+}
+
+// WithBridgeDrop makes Bridge keep at most one undelivered event and drop wakes, which occur while it is pending,
+// instead of waiting for the receiver. The bridge goroutine waits again right away, so it does not miss signals, but coalesces them.
+func WithBridgeDrop() BridgeOption {
+	return func(o *bridgeOptions) {
+		o.drop = true
+	}
+}
+
+// Bridge adapts Cond/RWCond into a channel: it starts a goroutine, which waits on Cond/RWCond without locker and sends
+// an event on the returned channel for every wake (signal or broadcast). The channel is closed and the goroutine exits,
+// when Cond/RWCond is closed or ctx is cancelled, so `for range c.Bridge(ctx)` ends cleanly.
+// The goroutine is counted by WaitCount and takes Signal wakes like any other waiter. By default it blocks until the event is received,
+// and signals sent meanwhile do not find it waiting (see [WithBridgeDrop] for the other policy).
+func (c *commonCond) Bridge(ctx context.Context, opts ...BridgeOption) <-chan struct{} {
+	var o bridgeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ch := make(chan struct{})
+	if o.drop {
+		ch = make(chan struct{}, 1)
+	}
+	go c.bridge(ctx, ch, o.drop)
+	return ch
+}
+
+func (c *commonCond) bridge(ctx context.Context, ch chan<- struct{}, drop bool) {
+	defer close(ch)
+	for {
+		// The goroutine exits for good on cancellation, so it is credited to SignalWithContext calls in flight.
+		ok, err := c.parkContext(nopLocker{}, ctx, true)
+		if !ok || err != nil {
+			return
+		}
+		if drop {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		select {
+		case ch <- struct{}{}:
+		case <-ctx.Done():
+			return
+		case <-c.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package cond_test
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+// signalDelivered signals until the signal wakes a goroutine.
+func signalDelivered(c *Cond) {
+	for c.Signal(1) == 0 {
+		runtime.Gosched()
+	}
+}
+
+func TestBridge(t *testing.T) {
+	c := New(&sync.Mutex{})
+	ctx, cancel := context.WithCancel(context.Background())
+	events := c.Bridge(ctx)
+	for i := 0; i < 5; i++ {
+		signalDelivered(c)
+		<-events
+	}
+	select {
+	case <-events:
+		t.Fatal("want one event per signal")
+	default:
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Fatal("want channel closed on cancel")
+	}
+	if n := c.WaitCount(); n != 0 {
+		t.Fatalf("want bridge goroutine gone, got %d waiters", n)
+	}
+
+	events = c.Bridge(context.Background())
+	waitParked(c, 1)
+	c.Close()
+	if _, ok := <-events; ok {
+		t.Fatal("want channel closed on close")
+	}
+}
+
+func TestBridgeDrop(t *testing.T) {
+	c := New(&sync.Mutex{})
+	ctx, cancel := context.WithCancel(context.Background())
+	events := c.Bridge(ctx, WithBridgeDrop())
+	// Nobody receives, but the bridge keeps waiting and coalesces wakes into a single event.
+	for i := 0; i < 10; i++ {
+		signalDelivered(c)
+	}
+	cancel()
+	n := 0
+	for range events {
+		n++
+	}
+	// One event was pending, and the last wake may be sent while the channel is drained.
+	if n < 1 || n > 2 {
+		t.Fatalf("want wakes coalesced into one or two events, got %d", n)
+	}
+}
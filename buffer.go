@@ -0,0 +1,83 @@
+package cond
+
+import (
+	"context"
+	"sync"
+)
+
+// buffer retains up to n signals, which found no waiting goroutine, in the buffer of [WithSignalBuffer] and reports
+// how many goroutines were awoken by them instead and how many signals were retained (excess signals of a full buffer are dropped). A goroutine, which was counted by WaitCount before the signals were retained,
+// may have checked the buffer before it, so buffered signals are handed to counted goroutines right away.
+func (c *commonCond) buffer(n int) (woken, retained int) {
+	size := int64(c.opts.signalBuffer)
+	for !c.closed.Load() {
+		cur := c.buffered.Load()
+		add := min(size-cur, int64(n))
+		if add <= 0 {
+			break
+		}
+		if c.buffered.CompareAndSwap(cur, cur+add) {
+			retained = int(add)
+			break
+		}
+	}
+	for c.s.WaitCount() > 0 && c.takeBuffered() {
+		delivered := false
+		for c.s.WaitCount() > 0 {
+			if c.s.Signal(1) == 1 {
+				delivered = true
+				break
+			}
+		}
+		if !delivered {
+			// The goroutine has gone (e.g. it took a buffered signal itself), keep the signal for the next one.
+			c.buffered.Add(1)
+			break
+		}
+		woken++
+	}
+	return woken, retained
+}
+
+// takeBuffered consumes a buffered signal and reports if there was one. Close drops buffered signals.
+func (c *commonCond) takeBuffered() bool {
+	for !c.closed.Load() {
+		cur := c.buffered.Load()
+		if cur <= 0 {
+			return false
+		}
+		if c.buffered.CompareAndSwap(cur, cur-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// BufferedSignals returns current number of signals retained by [WithSignalBuffer] (0 after Close).
+func (c *commonCond) BufferedSignals() int {
+	if c.closed.Load() {
+		return 0
+	}
+	return int(c.buffered.Load())
+}
+
+// bufferLocker wraps l and checks the buffer once wake counted the goroutine: signals buffered after the check are handed to
+// the goroutine by Signal, and signals buffered before it cancel the wait.
+type bufferLocker struct {
+	l      sync.Locker
+	c      *commonCond
+	cancel context.CancelFunc
+	took   bool
+}
+
+func (l *bufferLocker) Lock() {
+	l.l.Lock()
+}
+
+func (l *bufferLocker) Unlock() {
+	l.l.Unlock()
+	if l.c.takeBuffered() {
+		l.took = true
+		l.cancel()
+	}
+}
@@ -0,0 +1,87 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWithSignalBuffer(t *testing.T) {
+	c := New(&sync.Mutex{}, WithSignalBuffer(2))
+	if n := c.Signal(3); n != 0 {
+		t.Fatalf("want nobody awoken, got %d", n)
+	}
+	if n := c.BufferedSignals(); n != 2 {
+		t.Fatalf("want 2 buffered signals, got %d", n)
+	}
+	c.Broadcast()
+	if n := c.BufferedSignals(); n != 2 {
+		t.Fatalf("want broadcast not buffered, got %d", n)
+	}
+
+	// Later waiters are released from the buffer.
+	c.L.Lock()
+	for i := 0; i < 2; i++ {
+		if !c.Wait() {
+			t.Fatal("want true")
+		}
+	}
+	c.L.Unlock()
+	if n := c.BufferedSignals(); n != 0 {
+		t.Fatalf("want empty buffer, got %d", n)
+	}
+
+	// The buffer is empty, so the next wait parks.
+	parked, result := c.WaitReady()
+	<-parked
+	if n := c.Signal(1); n != 1 {
+		t.Fatalf("want 1 awoken, got %d", n)
+	}
+	if !<-result {
+		t.Fatal("want true")
+	}
+
+	c.Signal(1)
+	c.Close()
+	if n := c.BufferedSignals(); n != 0 {
+		t.Fatalf("want buffered signals dropped by close, got %d", n)
+	}
+	c.L.Lock()
+	defer c.L.Unlock()
+	if c.Wait() {
+		t.Fatal("want buffered signals dropped by close")
+	}
+}
+
+func TestWithSignalBufferNoStrandedSignals(t *testing.T) {
+	// Every signal either wakes a goroutine or is buffered, so all waits finish, even though signals race with parking.
+	waiters, waits := 4, 500
+	c := New(&sync.Mutex{}, WithSignalBuffer(waiters*waits))
+	var wg sync.WaitGroup
+	for i := 0; i < waiters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.L.Lock()
+			defer c.L.Unlock()
+			for j := 0; j < waits; j++ {
+				c.Wait()
+			}
+		}()
+	}
+	for i := 0; i < waiters*waits; i++ {
+		c.Signal(1)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("waits are stuck: %d parked, %d buffered", c.WaitCount(), c.BufferedSignals())
+	}
+}
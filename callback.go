@@ -0,0 +1,31 @@
+package cond
+
+import "sync"
+
+func (c *commonCond) waitWithDone(l sync.Locker, onWake func(woken bool)) bool {
+	ok := c.park(l)
+	if onWake != nil {
+		callSafely(onWake, ok)
+	}
+	return ok
+}
+
+// callSafely calls f and drops its panic, so a faulty callback can not break the wait path.
+func callSafely(f func(bool), v bool) {
+	defer func() {
+		_ = recover()
+	}()
+	f(v)
+}
+
+// WaitWithDone is same as [Cond.Wait], but calls onWake (if not nil) exactly once with Wait's result on the waiting goroutine
+// after locker is Locked again, e.g. to record the wake timestamp. A panic in onWake is recovered and dropped.
+func (c *Cond) WaitWithDone(onWake func(woken bool)) bool {
+	return c.waitWithDone(c.L, onWake)
+}
+
+// WaitWithDone is same as [RWCond.Wait], but calls onWake (if not nil) exactly once with Wait's result after locker is RLocked again
+// (see [Cond.WaitWithDone]).
+func (c *RWCond) WaitWithDone(onWake func(woken bool)) bool {
+	return c.waitWithDone(c.rwl, onWake)
+}
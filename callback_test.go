@@ -0,0 +1,59 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWaitWithDone(t *testing.T) {
+	var m sync.Mutex
+	c := New(&m)
+	type call struct {
+		woken, locked bool
+	}
+	calls := make(chan call, 2)
+	wait := func(onWake func(bool)) <-chan bool {
+		done := make(chan bool, 1)
+		go func() {
+			m.Lock()
+			done <- c.WaitWithDone(onWake)
+			m.Unlock()
+		}()
+		return done
+	}
+	record := func(woken bool) {
+		calls <- call{woken: woken, locked: !m.TryLock()}
+	}
+
+	done := wait(record)
+	waitParked(c, 1)
+	c.Signal(1)
+	if !<-done {
+		t.Fatal("want true")
+	}
+	if got := <-calls; !got.woken || !got.locked {
+		t.Fatalf("want callback with true and locker held, got %+v", got)
+	}
+
+	// A panicking callback does not break the wait.
+	done = wait(func(bool) { panic("boom") })
+	waitParked(c, 1)
+	c.Signal(1)
+	if !<-done {
+		t.Fatal("want true despite callback panic")
+	}
+
+	c.Close()
+	done = wait(record)
+	if <-done {
+		t.Fatal("want false for closed Cond")
+	}
+	if got := <-calls; got.woken || !got.locked {
+		t.Fatalf("want callback with false and locker held, got %+v", got)
+	}
+	if len(calls) != 0 {
+		t.Fatal("want exactly one callback per wait")
+	}
+}
@@ -0,0 +1,26 @@
+package cond
+
+import "sync"
+
+// NewFromChannel returns Cond with associated locker (see [New]), which broadcasts on every value received from ch.
+// It starts a goroutine, which receives from ch until ch is closed or Cond is closed. Closing ch closes Cond.
+func NewFromChannel(l sync.Locker, ch <-chan struct{}, opts ...Option) *Cond {
+	c := New(l, opts...)
+	go c.forward(ch)
+	return c
+}
+
+func (c *commonCond) forward(ch <-chan struct{}) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case _, ok := <-ch:
+			if !ok {
+				c.Close()
+				return
+			}
+			c.broadcast()
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package cond_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestNewFromChannel(t *testing.T) {
+	ch := make(chan struct{})
+	c := NewFromChannel(&sync.Mutex{}, ch)
+
+	results := make(chan bool)
+	wait := func() {
+		go func() {
+			c.L.Lock()
+			results <- c.Wait()
+			c.L.Unlock()
+		}()
+	}
+
+	wait()
+	wait()
+	waitParked(c, 2)
+	ch <- struct{}{}
+	if !<-results || !<-results {
+		t.Fatal("want true for broadcast")
+	}
+
+	wait()
+	waitParked(c, 1)
+	close(ch)
+	if <-results {
+		t.Fatal("want false after channel was closed")
+	}
+	if !c.IsClosed() {
+		t.Fatal("want Cond to be closed")
+	}
+}
+
+func TestNewFromChannelClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+	c := NewFromChannel(&sync.Mutex{}, make(chan struct{}))
+	c.Close()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatal("forwarding goroutine leaked")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
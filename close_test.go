@@ -0,0 +1,279 @@
+package cond_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestCloseHooks(t *testing.T) {
+	var m sync.Mutex
+	var order []string
+	state := "running"
+	var c *Cond
+	c = New(&m,
+		WithPreCloseHook(func() {
+			if !c.IsClosed() {
+				t.Error("want IsClosed in pre-close hook")
+			}
+			m.Lock()
+			state = "closed"
+			order = append(order, "pre-close")
+			m.Unlock()
+		}),
+		WithOnClose(func() {
+			m.Lock()
+			order = append(order, "on-close")
+			m.Unlock()
+		}),
+	)
+
+	n := 5
+	observed := make(chan string, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			m.Lock()
+			for c.Wait() {
+			}
+			observed <- state
+			m.Unlock()
+		}()
+	}
+	waitParked(c, n)
+
+	if !c.Close() {
+		t.Fatal("want true for the first Close")
+	}
+	if c.Close() {
+		t.Fatal("want false for the second Close")
+	}
+	for i := 0; i < n; i++ {
+		if got := <-observed; got != "closed" {
+			t.Fatalf("waiter observed %q, want pre-close state", got)
+		}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	if len(order) != 2 || order[0] != "pre-close" || order[1] != "on-close" {
+		t.Fatalf("want hooks to run once in order, got %v", order)
+	}
+}
+
+func TestClosePreCloseHookPanics(t *testing.T) {
+	onClose := make(chan struct{})
+	c := New(&sync.Mutex{},
+		WithPreCloseHook(func() { panic("hook") }),
+		WithOnClose(func() { close(onClose) }),
+	)
+	done := make(chan bool)
+	go func() {
+		c.L.Lock()
+		done <- c.Wait()
+		c.L.Unlock()
+	}()
+	waitParked(c, 1)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "hook" {
+				t.Fatalf("want hook panic, got %v", r)
+			}
+		}()
+		c.Close()
+	}()
+	if <-done {
+		t.Fatal("want waiter woken by close")
+	}
+	<-onClose
+	c.L.Lock()
+	defer c.L.Unlock()
+	if c.Wait() {
+		t.Fatal("want Wait on closed cond to return false")
+	}
+}
+
+func TestCloseWakes(t *testing.T) {
+	for iter := 0; iter < 20; iter++ {
+		c := New(&sync.Mutex{})
+		n := 200
+		ctx, cancel := context.WithCancel(context.Background())
+		var cancelled atomic.Int64
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				wctx := context.Background()
+				if i%2 == 0 {
+					wctx = ctx
+				}
+				c.L.Lock()
+				if _, err := c.WaitWithContext(wctx); err != nil {
+					cancelled.Add(1)
+				}
+				c.L.Unlock()
+			}(i)
+		}
+		waitParked(c, n)
+		go cancel()
+		c.Close()
+		wg.Wait()
+
+		if got := cancelled.Load() + int64(c.CloseWakes()); got != int64(n) {
+			t.Fatalf("cancelled %d + closed %d != %d waiters", cancelled.Load(), c.CloseWakes(), n)
+		}
+	}
+
+	c := New(&sync.Mutex{})
+	c.Close()
+	c.L.Lock()
+	c.Wait()
+	c.L.Unlock()
+	if c.CloseWakes() != 0 {
+		t.Fatal("wait on closed Cond must not be counted")
+	}
+}
+
+func TestStrictUseAfterClose(t *testing.T) {
+	expectPanic := func(want string, wait func()) {
+		t.Helper()
+		defer func() {
+			t.Helper()
+			if r := recover(); r != want {
+				t.Fatalf("want panic %q, got %v", want, r)
+			}
+		}()
+		wait()
+	}
+
+	c := New(&sync.Mutex{}, WithStrictUseAfterClose(), WithName("orders"))
+	// Waits parked before Close return false.
+	parked, result := c.WaitReady()
+	<-parked
+	c.Close()
+	if <-result {
+		t.Fatal("want false for wait parked before Close")
+	}
+	c.L.Lock()
+	defer c.L.Unlock()
+	expectPanic(`cond: Wait on closed Cond "orders"`, func() { c.Wait() })
+	expectPanic(`cond: Wait on closed Cond "orders"`, func() { c.WaitWithContext(context.Background()) })
+
+	rw := NewRW(&sync.RWMutex{}, WithStrictUseAfterClose())
+	rw.Close()
+	rw.L.RLock()
+	defer rw.L.RUnlock()
+	expectPanic("cond: Wait on closed Cond", func() { rw.Wait() })
+}
+
+func TestLenientUseAfterClose(t *testing.T) {
+	c := New(&sync.Mutex{}, WithName("orders"))
+	c.Close()
+	c.L.Lock()
+	defer c.L.Unlock()
+	if c.Wait() {
+		t.Fatal("want false for closed Cond")
+	}
+	if ok, err := c.WaitWithContext(context.Background()); ok || err != nil {
+		t.Fatalf("want false and nil, got %v and %v", ok, err)
+	}
+}
+
+func TestErrorOnClose(t *testing.T) {
+	c := New(&sync.Mutex{}, WithErrorOnClose())
+	done := make(chan error)
+	go func() {
+		c.L.Lock()
+		defer c.L.Unlock()
+		_, err := c.WaitWithContext(context.Background())
+		done <- err
+	}()
+	waitParked(c, 1)
+	c.Close()
+	err := <-done
+	if !errors.Is(err, ErrClosed) || errors.Is(err, context.Canceled) {
+		t.Fatalf("want ErrClosed on close, got %v", err)
+	}
+
+	c.L.Lock()
+	defer c.L.Unlock()
+	if _, err := c.WaitWithContextCause(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("WaitWithContextCause: want ErrClosed, got %v", err)
+	}
+	if _, _, err := c.WaitTimedWithContext(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("WaitTimedWithContext: want ErrClosed, got %v", err)
+	}
+	if _, err := c.WaitForTimeout(func() bool { return false }, time.Second); !errors.Is(err, ErrClosed) {
+		t.Fatalf("WaitForTimeout: want ErrClosed, got %v", err)
+	}
+	if _, err := c.WaitForBudget(func() bool { return false }, 1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("WaitForBudget: want ErrClosed, got %v", err)
+	}
+	if _, err := c.WaitForErr(func() (bool, error) { return false, nil }); !errors.Is(err, ErrClosed) {
+		t.Fatalf("WaitForErr: want ErrClosed, got %v", err)
+	}
+
+	// Cancellation is still reported as the context error.
+	c2 := New(&sync.Mutex{}, WithErrorOnClose())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c2.L.Lock()
+	_, err = c2.WaitWithContext(ctx)
+	c2.L.Unlock()
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrClosed) {
+		t.Fatalf("want context.Canceled on cancel, got %v", err)
+	}
+}
+
+func TestCloseWithoutErrorOnClose(t *testing.T) {
+	c := New(&sync.Mutex{})
+	c.Close()
+	c.L.Lock()
+	defer c.L.Unlock()
+	if ok, err := c.WaitWithContext(context.Background()); ok || err != nil {
+		t.Fatalf("want false and nil, got %v and %v", ok, err)
+	}
+}
+
+func TestCloseRacingLateWaits(t *testing.T) {
+	for round := 0; round < 50; round++ {
+		c := New(&sync.Mutex{})
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				c.L.Lock()
+				defer c.L.Unlock()
+				// Keep waiting until Close is observed: every late Wait must return.
+				for c.Wait() || !c.IsClosed() {
+				}
+				if _, err := c.WaitWithContext(context.Background()); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		close(start)
+		go c.Broadcast()
+		c.Close()
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("round %d: waits parked after Close", round)
+		}
+	}
+}
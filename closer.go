@@ -0,0 +1,54 @@
+package cond
+
+import (
+	"errors"
+	"io"
+	"slices"
+)
+
+// AddCloser registers cl to be closed, when Cond/RWCond is closed. Closers are closed in reverse order of registration (LIFO)
+// after all waiting goroutines were woken, so closer errors or slow closers never keep waiters parked.
+// Returns false and does not register cl, if Cond/RWCond is already closed.
+func (c *commonCond) AddCloser(cl io.Closer) bool {
+	c.closersMu.Lock()
+	defer c.closersMu.Unlock()
+	if c.closed.Load() {
+		return false
+	}
+	c.closers = append(c.closers, cl)
+	return true
+}
+
+// removeCloser unregisters cl, which was registered by AddCloser, so it is not closed and not retained by Cond/RWCond.
+func (c *commonCond) removeCloser(cl io.Closer) {
+	c.closersMu.Lock()
+	defer c.closersMu.Unlock()
+	if i := slices.Index(c.closers, cl); i >= 0 {
+		c.closers = slices.Delete(c.closers, i, i+1)
+	}
+}
+
+// CloseWithErrors is same as [commonCond.Close], but returns errors of closers registered by [commonCond.AddCloser] joined by errors.Join.
+// If Cond/RWCond is closed by another call, CloseWithErrors waits until all closers were closed and returns the same error.
+func (c *commonCond) CloseWithErrors() error {
+	c.Close()
+	<-c.closersDone
+	return c.closeErr
+}
+
+// runClosers closes registered closers in LIFO order and publishes joined error via closersDone.
+func (c *commonCond) runClosers() {
+	c.closersMu.Lock()
+	closers := c.closers
+	c.closers = nil
+	c.closersMu.Unlock()
+
+	var errs []error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	c.closeErr = errors.Join(errs...)
+	close(c.closersDone)
+}
@@ -0,0 +1,67 @@
+package cond_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+type testCloser struct {
+	err   error
+	order *[]int
+	id    int
+}
+
+func (c testCloser) Close() error {
+	*c.order = append(*c.order, c.id)
+	return c.err
+}
+
+func TestCloseWithErrors(t *testing.T) {
+	c := New(&sync.Mutex{})
+	var order []int
+	errA, errB := errors.New("a"), errors.New("b")
+	c.AddCloser(testCloser{err: errA, order: &order, id: 1})
+	c.AddCloser(testCloser{order: &order, id: 2})
+	c.AddCloser(testCloser{err: errB, order: &order, id: 3})
+
+	done := make(chan bool)
+	go func() {
+		c.L.Lock()
+		done <- c.Wait()
+		c.L.Unlock()
+	}()
+	waitParked(c, 1)
+
+	err := c.CloseWithErrors()
+	if <-done {
+		t.Fatal("want waiter to be woken by close despite closer errors")
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("want joined closer errors, got %v", err)
+	}
+	if len(order) != 3 || order[0] != 3 || order[1] != 2 || order[2] != 1 {
+		t.Fatalf("want LIFO order [3 2 1], got %v", order)
+	}
+	if c.CloseWithErrors() != err {
+		t.Fatal("want same error for subsequent calls")
+	}
+	if c.AddCloser(testCloser{order: &order, id: 4}) {
+		t.Fatal("want false for closed Cond")
+	}
+	if len(order) != 3 {
+		t.Fatal("closer was closed twice or after Close")
+	}
+}
+
+func TestCloseWithErrorsNoClosers(t *testing.T) {
+	c := NewRW(&sync.RWMutex{})
+	if !c.Close() {
+		t.Fatal("want true")
+	}
+	if err := c.CloseWithErrors(); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+}
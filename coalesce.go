@@ -0,0 +1,27 @@
+package cond
+
+// SignalCoalesced is same as Signal(1), but does nothing and returns 0, if the previous SignalCoalesced used the same token
+// and its signal was not consumed yet, so a burst of identical events wakes a goroutine only once.
+// A signal is consumed, when any waiting goroutine is awoken by signal or broadcast. A signal, which woke no goroutine, is not pending,
+// even if it was retained by [WithSignalBuffer].
+// Only the last token is remembered: interleaved tokens (A, B, A) are all delivered. Concurrent calls with the same token
+// are coalesced on a best effort basis and may both signal.
+func (c *commonCond) SignalCoalesced(token uint64) int {
+	if c.coalescePending.Load() && c.coalesceToken.Load() == token {
+		return 0
+	}
+	c.coalesceToken.Store(token)
+	c.coalescePending.Store(true)
+	n := c.Signal(1)
+	if n == 0 {
+		c.coalescePending.Store(false)
+	}
+	return n
+}
+
+// consume marks a coalesced signal as consumed. It is called by awoken goroutines.
+func (c *commonCond) consume() {
+	if c.coalescePending.Load() {
+		c.coalescePending.Store(false)
+	}
+}
@@ -0,0 +1,65 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestSignalCoalesced(t *testing.T) {
+	c := New(&sync.Mutex{})
+	n := 3
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.L.Lock()
+			c.Wait()
+			c.L.Unlock()
+		}()
+	}
+	waitParked(c, n)
+
+	// Hold the locker, so the awoken goroutine can not consume the signal yet.
+	c.L.Lock()
+	if m := c.SignalCoalesced(1); m != 1 {
+		t.Fatalf("want 1, got %d", m)
+	}
+	for i := 0; i < 10; i++ {
+		if m := c.SignalCoalesced(1); m != 0 {
+			t.Fatalf("burst: want 0, got %d", m)
+		}
+	}
+	if m := c.SignalCoalesced(2); m != 1 {
+		t.Fatalf("new token: want 1, got %d", m)
+	}
+	if m := c.SignalCoalesced(1); m != 1 {
+		t.Fatalf("interleaved token: want 1, got %d", m)
+	}
+	c.L.Unlock()
+	wg.Wait()
+}
+
+func TestSignalCoalescedConsumed(t *testing.T) {
+	c := New(&sync.Mutex{})
+	if m := c.SignalCoalesced(1); m != 0 {
+		t.Fatalf("no waiters: want 0, got %d", m)
+	}
+	for i := 0; i < 2; i++ {
+		done := make(chan struct{})
+		go func() {
+			c.L.Lock()
+			c.Wait()
+			c.L.Unlock()
+			close(done)
+		}()
+		waitParked(c, 1)
+		// The signal without waiters and the consumed one are not pending.
+		if m := c.SignalCoalesced(1); m != 1 {
+			t.Fatalf("want 1, got %d", m)
+		}
+		<-done
+	}
+}
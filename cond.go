@@ -2,29 +2,154 @@ package cond
 
 import (
 	"context"
+	"io"
+	"log/slog"
+	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/nursik/wake"
 )
 
+// CondLike is the method set shared by [Cond] and [RWCond]. It lets code depend on an interface, so a fake can be injected in tests.
+type CondLike interface {
+	Wait() bool
+	WaitWithContext(ctx context.Context) (bool, error)
+	Signal(n int) int
+	Broadcast()
+	Close() bool
+	IsClosed() bool
+	WaitCount() int
+}
+
+var (
+	_ CondLike = (*Cond)(nil)
+	_ CondLike = (*RWCond)(nil)
+)
+
 type commonCond struct {
-	s *wake.Signaller
-	r *wake.Receiver
+	s    *wake.Signaller
+	r    *wake.Receiver
+	opts options
+	done chan struct{}
+
+	closed     atomic.Bool
+	closeWakes atomic.Int64
+
+	signalled atomic.Uint64
+	spins     atomic.Uint64
+	missed    atomic.Uint64
+	seq       atomic.Uint64
+	tokens    tokens
+	order     wakeOrder
+	queue     *waiterQueue
+	buffered  atomic.Int64
+	lastOut   atomic.Int64
+	rate      *signalRate
+	timeout   atomic.Int64
+	guard     atomic.Pointer[func() bool]
+
+	inflight     atomic.Int64
+	cancels      atomic.Uint64
+	cancelNotice atomic.Pointer[notice]
+
+	epochMu      sync.Mutex
+	epochs       uint64
+	epoch        atomic.Uint64
+	epochSeq     atomic.Uint64
+	broadcasting atomic.Uint64
+	broadcasts   atomic.Uint64
+
+	// instrumented is set if any option needs park hooks (see park).
+	instrumented bool
+	ewma         atomic.Uint64
+	scaleKick    chan struct{}
+	scaleTarget  atomic.Int64
+	waitersMu    sync.Mutex
+	waiters      map[*waiter]struct{}
+
+	paused     atomic.Bool
+	pauseMu    sync.Mutex
+	pending    int
+	pendingAll bool
+
+	latched atomic.Bool
+
+	coalesceToken   atomic.Uint64
+	coalescePending atomic.Bool
+
+	closersMu   sync.Mutex
+	closers     []io.Closer
+	closersDone chan struct{}
+	closeErr    error
+
+	// shutdownKey is c as registered by RegisterForShutdown, guarded by shutdownRegistry.mu.
+	shutdownKey CondLike
+}
+
+func (c *commonCond) init(opts []Option) {
+	c.s, c.r = wake.New()
+	c.opts = newOptions(opts)
+	c.done = make(chan struct{})
+	c.closersDone = make(chan struct{})
+	c.instrumented = c.opts.ewmaAlpha > 0 || c.opts.lockContention != nil || c.opts.scale != nil || c.opts.stackCapture || c.opts.logger != nil ||
+		c.opts.wakeOrder
+	if c.opts.heartbeat > 0 {
+		go c.heartbeat(c.opts.heartbeat)
+	}
+	if c.opts.deadlockTimeout > 0 {
+		handler := c.opts.deadlockHandler
+		if handler == nil {
+			handler = logDeadlock
+		}
+		go c.detectDeadlock(c.opts.deadlockTimeout, handler)
+	}
+	c.timeout.Store(int64(c.opts.defaultTimeout))
+	if c.opts.signalRate {
+		c.rate = newSignalRate()
+	}
+	if c.opts.scale != nil {
+		c.scaleKick = make(chan struct{}, 1)
+		c.scaleTarget.Store(int64(c.opts.scale.clamp(c.opts.scale.scale(0))))
+		go c.runScale(*c.opts.scale)
+	}
+	if c.opts.expvar != "" {
+		c.publish(c.opts.expvar)
+	}
+	if c.opts.waiterQueue > 0 {
+		c.queue = &waiterQueue{slots: make(chan struct{}, c.opts.waiterQueue)}
+	}
+	if c.opts.parent != nil {
+		c.attach(c.opts.parent)
+	}
 }
 
 // Signal wakes n goroutines (if there are any) and reports how many goroutines were awoken.
-// If n <= 0 it wakes all goroutines and returns 0 (same as [commonCond.Broadcast]).
+// If n <= 0 it wakes all goroutines (same as [commonCond.Broadcast]) and reports how many goroutines were waiting right before it,
+// so the result is always the number of woken goroutines. A broadcast may also count goroutines concurrently woken by other signals.
+// A parked goroutine returns once per wait, even if a signal and a broadcast race for it, and Signal only counts completed handoffs,
+// so the sum of counts reported by concurrent Signal calls never exceeds the number of waiting goroutines.
+// Any n > 0 up to math.MaxInt is safe: Signal stops as soon as no goroutine is waiting for the direct wake, so once it woke
+// a goroutine, it may miss goroutines, which are counted by WaitCount, but not parked yet.
+// Parked goroutines are woken in the order they reached wake's channel queue, so repeated partial signals rotate through
+// parked waiters instead of waking the same ones. The order is fixed only once a goroutine is parked: a goroutine, which is
+// already counted by WaitCount, but still on its way to the queue, may be overtaken by any number of other waits.
 func (c *commonCond) Signal(n int) int {
 	if n <= 0 {
-		c.s.Broadcast()
+		return c.broadcast()
+	}
+	if c.paused.Load() && c.hold(n) {
 		return 0
 	}
+	c.delivered()
 
+	orig := n
 	var x int
 	// we need to notify at least one receiver if we know that at least one is waiting.
 	// we are doing it in for loop, because unlike golang's sync.Cond we may start waiting after sending Signal.
 	// golang's sync.Cond Wait() appends to notification_list before unlocking.
 	for c.s.WaitCount() > 0 {
+		c.spins.Add(1)
 		x = c.s.Signal(n)
 		n = n - x
 		if x > 0 {
@@ -32,38 +157,275 @@ func (c *commonCond) Signal(n int) int {
 		}
 	}
 	// don't accidentally broadcast
-	if n == 0 {
-		return x
+	if n != 0 {
+		x += c.s.Signal(n)
+	}
+	var retained int
+	if c.opts.signalBuffer > 0 && x < orig {
+		var woken int
+		woken, retained = c.buffer(orig - x)
+		x += woken
+	}
+	if x == 0 && retained == 0 && !c.s.IsClosed() {
+		c.missed.Add(1)
+	}
+	c.signalled.Add(uint64(x))
+	return x
+}
+
+// MissedSignals reports how many Signal and broadcast calls found no waiting goroutines and woke nobody.
+// A growing value means that signals are lost, because consumers are not waiting, when producers signal.
+// Signal calls, whose signals were retained by [WithSignalBuffer], are not missed, as they are handed to the next waits.
+// Heartbeats of [WithHeartbeat] are not counted.
+// Calls on closed Cond/RWCond and SignalWithContext, which waits for goroutines, are not counted. Signals held by [commonCond.Pause]
+// are counted once by Resume, if they wake nobody.
+func (c *commonCond) MissedSignals() uint64 {
+	return c.missed.Load()
+}
+
+// SignalSpinIterations reports the total number of iterations of Signal's internal loop, which retries while a goroutine is counted
+// by WaitCount, but is not receiving yet (it is between counting and parking). Each Signal call, which finds a parked goroutine
+// at once, makes one iteration, so a value much higher than the number of Signal calls means Signal spins on waiters, which are slow to park.
+func (c *commonCond) SignalSpinIterations() uint64 {
+	return c.spins.Load()
+}
+
+// SignalUntilEmpty wakes goroutines one by one while WaitCount() > 0, calling perWake (if not nil) after each awoken goroutine,
+// and reports how many goroutines were awoken. Unlike Broadcast it lets the caller pace the drain.
+// Goroutines, which start waiting during the drain, are awoken too, so it may not return while new waiters keep arriving.
+// It returns immediately if Cond/RWCond is paused.
+func (c *commonCond) SignalUntilEmpty(perWake func()) int {
+	var total int
+	for c.s.WaitCount() > 0 && !c.paused.Load() {
+		if c.Signal(1) == 0 {
+			continue
+		}
+		total++
+		if perWake != nil {
+			perWake()
+		}
 	}
-	return x + c.s.Signal(n)
+	return total
+}
+
+// SignalChecked is same as [commonCond.Signal], but returns 0 and [ErrClosed], if Cond/RWCond is closed.
+// Signal silently does nothing on closed Cond/RWCond, which may hide signals sent after shutdown.
+func (c *commonCond) SignalChecked(n int) (int, error) {
+	if c.IsClosed() {
+		return 0, ErrClosed
+	}
+	return c.Signal(n), nil
+}
+
+// SignalExact is same as [commonCond.Signal], but also reports shortfall, i.e. n - woken.
+// Unlike Signal it never broadcasts: if n <= 0, it wakes nobody and returns 0 and 0.
+func (c *commonCond) SignalExact(n int) (woken int, shortfall int) {
+	if n <= 0 {
+		return 0, 0
+	}
+	woken = c.Signal(n)
+	return woken, n - woken
 }
 
 // SignalWithContext wakes n goroutines and reports how many goroutines were awoken and ctx.Err() if context was cancelled.
 // It is a blocking operation and will be finished when all n goroutines are awoken, context is cancelled or Cond/RWCond was closed.
-// If n <= 0, it wakes all goroutines (same as [commonCond.Broadcast]) regardless of context cancellation.
+// A goroutine, which stops waiting due to its own context cancellation while SignalWithContext is in flight, counts toward n
+// (but not toward the result), so the signaller does not keep blocking for a goroutine, which is gone. The cancellation is counted,
+// once that goroutine re-acquires locker, so call SignalWithContext without holding locker. With several signallers in flight
+// a cancellation counts toward each of them.
+// If n <= 0, it wakes all goroutines (same as [commonCond.Broadcast]) regardless of context cancellation and reports them as [commonCond.Signal].
 func (c *commonCond) SignalWithContext(ctx context.Context, n int) (int, error) {
-	return c.s.SignalWithContext(ctx, n)
+	if n <= 0 {
+		return c.broadcast(), nil
+	}
+	if c.paused.Load() && c.hold(n) {
+		return 0, nil
+	}
+	c.delivered()
+	x, err := c.signalCrediting(ctx, n)
+	c.signalled.Add(uint64(x))
+	return x, err
 }
 
 // Broadcast wakes up all goroutines.
+// Like other signalling methods, it does not use locker, so it may be called with or without locker held,
+// including by a just awoken goroutine, which holds locker again (e.g. to cascade wakes).
 func (c *commonCond) Broadcast() {
+	c.broadcast()
+}
+
+// broadcastAndSet runs set and broadcasts with l locked.
+func (c *commonCond) broadcastAndSet(l sync.Locker, set func()) int {
+	l.Lock()
+	defer l.Unlock()
+	set()
+	return c.broadcast()
+}
+
+// broadcast wakes up all goroutines and reports how many goroutines were waiting right before it.
+func (c *commonCond) broadcast() int {
+	return c.broadcastFrom(true)
+}
+
+// broadcastFrom is same as broadcast. Broadcasts, which Cond/RWCond sends itself (heartbeats), pass caller false,
+// so they only advance Seq and are not counted by MissedSignals and SignalRate.
+func (c *commonCond) broadcastFrom(caller bool) int {
+	if c.paused.Load() && c.hold(0) {
+		return 0
+	}
+	if c.s.IsClosed() {
+		return 0
+	}
+	if caller {
+		c.delivered()
+	} else {
+		c.seq.Add(1)
+	}
+	n := c.s.WaitCount()
+	if n == 0 && caller {
+		c.missed.Add(1)
+	}
+	// broadcasting and broadcasts enclose the actual broadcast, see waitReason.
+	c.broadcasting.Add(1)
 	c.s.Broadcast()
+	c.broadcasts.Add(1)
+	if c.opts.broadcastObserver != nil {
+		c.opts.broadcastObserver(n)
+	}
+	return n
 }
 
 // Close closes Cond/RWCond and wakes all waiting goroutines.
 // The first Close() returns true and subsequent calls always return false.
+// Waking and preventing new waits is a single step: Close closes a channel, which every parked goroutine selects on,
+// so a Wait, which starts concurrently with Close, either observes closed on entry or is woken by it, and never parks indefinitely.
+// The first Close() runs hooks in this order: [WithPreCloseHook] hook (IsClosed already reports true, waiting goroutines are not awoken yet),
+// waking all waiting goroutines, closing closers registered by [commonCond.AddCloser], [WithOnClose] hook. Subsequent calls do not run hooks.
 func (c *commonCond) Close() bool {
-	return c.s.Close()
+	if c.closed.Swap(true) {
+		return false
+	}
+	// Close is completed even if preCloseHook panics, so Cond/RWCond is never left marked closed with goroutines still parked.
+	defer c.finishClose()
+	if c.opts.preCloseHook != nil {
+		c.opts.preCloseHook()
+	}
+	return true
+}
+
+// finishClose wakes all waiting goroutines and runs the remaining close hooks.
+func (c *commonCond) finishClose() {
+	c.s.Close()
+	close(c.done)
+	if c.opts.logger != nil {
+		c.log(slog.LevelDebug, "cond: closed")
+	}
+	c.runClosers()
+	if c.opts.parent != nil {
+		c.detach(c.opts.parent)
+	}
+	c.unregisterForShutdown()
+	if c.opts.onClose != nil {
+		c.opts.onClose()
+	}
 }
 
 // IsClosed reports if Cond/RWCond is closed.
 func (c *commonCond) IsClosed() bool {
-	return c.s.IsClosed()
+	return c.closed.Load()
+}
+
+// CloseWakes reports how many waits were ended by Close, i.e. started before Close and returned false without context error.
+// Waits, which started on already closed Cond/RWCond, or ended due to context cancellation, are not counted.
+func (c *commonCond) CloseWakes() int {
+	return int(c.closeWakes.Load())
 }
 
 // WaitCount returns current number of goroutines waiting for signal.
+// The counter is maintained by wake: a goroutine is counted before it releases locker and uncounted exactly once
+// after it is awoken, regardless of the reason (signal, broadcast, close or context cancellation). Cond/RWCond never adjusts it.
+// Builds with -race panic if the invariant WaitCount() >= 0 is violated.
 func (c *commonCond) WaitCount() int {
-	return c.s.WaitCount()
+	n := c.s.WaitCount()
+	if raceEnabled && n < 0 {
+		panic("cond: negative WaitCount")
+	}
+	return n
+}
+
+// WaitCountBucketed reports the index of the first bucket, which is greater or equal to WaitCount(), or len(buckets) if there is none.
+// buckets must be sorted in ascending order. It allows to report coarse queue depth without revealing the exact number.
+func (c *commonCond) WaitCountBucketed(buckets []int) int {
+	return sort.SearchInts(buckets, c.s.WaitCount())
+}
+
+// closeError replaces the result of a wait, which ended by Close, with false and ErrClosed, if [WithErrorOnClose] is set.
+func (c *commonCond) closeError(ok bool, err error) (bool, error) {
+	if !ok && err == nil && c.opts.errorOnClose && c.closed.Load() {
+		return false, ErrClosed
+	}
+	return ok, err
+}
+
+func (c *commonCond) waitWithContext(l sync.Locker, ctx context.Context) (bool, error) {
+	return c.closeError(c.parkContext(l, ctx, true))
+}
+
+func (c *commonCond) waitWithContextCause(l sync.Locker, ctx context.Context) (bool, error) {
+	ok, err := c.parkContext(l, ctx, true)
+	if err != nil && ctx.Err() != nil {
+		return false, context.Cause(ctx)
+	}
+	return c.closeError(ok, err)
+}
+
+func (c *commonCond) waitWithContextEx(l sync.Locker, ctx context.Context) (bool, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, false, err
+	}
+	tl := &trackLocker{l: l}
+	ok, err := c.closeError(c.parkContext(tl, ctx, true))
+	return ok, tl.unlocked, err
+}
+
+func (c *commonCond) waitWithContexts(l sync.Locker, ctxs []context.Context) (bool, error) {
+	for _, ctx := range ctxs {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+	}
+	if len(ctxs) == 1 {
+		return c.waitWithContext(l, ctxs[0])
+	}
+	// context.AfterFunc does not spawn goroutines for contexts from the context package.
+	merged, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	for _, ctx := range ctxs {
+		stop := context.AfterFunc(ctx, func() {
+			cancel(ctx.Err())
+		})
+		defer stop()
+	}
+	ok, err := c.parkContext(l, merged, true)
+	if err != nil && merged.Err() != nil {
+		return false, context.Cause(merged)
+	}
+	return c.closeError(ok, err)
+}
+
+// trackLocker records if wake has released locker, i.e. the goroutine was parked.
+type trackLocker struct {
+	l        sync.Locker
+	unlocked bool
+}
+
+func (l *trackLocker) Lock() {
+	l.l.Lock()
+}
+
+func (l *trackLocker) Unlock() {
+	l.unlocked = true
+	l.l.Unlock()
 }
 
 type Cond struct {
@@ -72,49 +434,189 @@ type Cond struct {
 }
 
 // Wait Unlocks locker, blocks until awaken (returns true) or Cond was closed (returns false), and at the end Locks locker again.
+// With a default timeout (see [commonCond.SetDefaultWaitTimeout]) it also returns false, when the timeout elapses.
 func (c *Cond) Wait() bool {
-	return wake.UnsafeWait(c.r, c.L)
+	return c.wait(c.L)
 }
 
 // WaitWithContext Unlocks locker, blocks until awaken, context was cancelled or Cond was closed, and at the end Locks locker again.
 // Returns true and nil, if awaken by signal/broadcast.
-// Returns false and nil, if Cond was closed.
+// Returns false and nil, if Cond was closed (false and [ErrClosed] with [WithErrorOnClose]).
 // Returns false and ctx.Err(), if context was cancelled.
+// ctx.Done() is selected together with signals, so waits do not spawn goroutines regardless of how often they are cancelled.
 func (c *Cond) WaitWithContext(ctx context.Context) (bool, error) {
-	return wake.UnsafeWaitContext(c.r, c.L, ctx)
+	return c.waitWithContext(c.L, ctx)
+}
+
+// WaitWithContextCause is same as [Cond.WaitWithContext], but returns context.Cause(ctx) instead of ctx.Err(),
+// e.g. the cause passed to the cancel function of context.WithCancelCause. Without a cause it is same as ctx.Err().
+func (c *Cond) WaitWithContextCause(ctx context.Context) (bool, error) {
+	return c.waitWithContextCause(c.L, ctx)
+}
+
+// WaitWithContextEx is same as [Cond.WaitWithContext], but also reports if the goroutine was parked.
+// parked is false if Cond was already closed or ctx was already cancelled. In this case locker is never Unlocked.
+func (c *Cond) WaitWithContextEx(ctx context.Context) (woken bool, parked bool, err error) {
+	return c.waitWithContextEx(c.L, ctx)
+}
+
+// WaitWithContexts is same as [Cond.WaitWithContext], but unblocks when any of ctxs is cancelled and returns its error.
+// If several contexts are already cancelled on call, the first one in argument order is reported and locker is never Unlocked.
+func (c *Cond) WaitWithContexts(ctxs ...context.Context) (bool, error) {
+	return c.waitWithContexts(c.L, ctxs)
+}
+
+// BroadcastAndSet Locks locker, runs set to update shared state, broadcasts and Unlocks locker. Awoken goroutines re-acquire
+// locker only after set returned, so they always observe the change. Reports how many goroutines were waiting (see [commonCond.Signal]).
+// It acquires locker itself, so it must be called without holding it.
+func (c *Cond) BroadcastAndSet(set func()) int {
+	return c.broadcastAndSet(c.L, set)
 }
 
 // New returns Cond with associated locker. Same as sync.Cond in terms of usage, but has more functionality.
 // Only Wait and WaitWithContext methods use associated locker and other methods do not use locker. Using closed Cond is safe.
-// Slower than sync.Cond by ~3 times (sync.Cond's tests which only benchmarks broadcast).
-func New(l sync.Locker) *Cond {
-	s, r := wake.New()
-	return &Cond{
-		L: l,
-		commonCond: commonCond{
-			s: s,
-			r: r,
-		},
-	}
+// Slower than sync.Cond by ~3 times (sync.Cond's tests which only benchmarks broadcast). opts enable optional behavior, see [Option].
+// Waits park on channels, so Cond created inside a testing/synctest bubble is durably blocking and works with fake time.
+func New(l sync.Locker, opts ...Option) *Cond {
+	c := &Cond{L: l}
+	c.init(opts)
+	return c
 }
 
 type RWCond struct {
-	L   *sync.RWMutex
-	rwl rlocker
+	L         *sync.RWMutex
+	rwl       rlocker
+	upgraders atomic.Int64
+	writers   atomic.Int64
 	commonCond
 }
 
 // Wait RUnlocks locker, blocks until awaken (returns true) or RWCond was closed (returns false), and at the end RLocks locker again.
+// With a default timeout (see [commonCond.SetDefaultWaitTimeout]) it also returns false, when the timeout elapses.
 func (c *RWCond) Wait() bool {
-	return wake.UnsafeWait(c.r, c.rwl)
+	return c.wait(c.rwl)
 }
 
 // WaitWithContext RUnlocks locker, blocks until awaken, context was cancelled or RWCond was closed, and at the end RLocks locker again.
 // Returns true and nil, if awaken by signal/broadcast.
-// Returns false and nil, if RWCond was closed.
+// Returns false and nil, if RWCond was closed (false and [ErrClosed] with [WithErrorOnClose]).
 // Returns false and ctx.Err(), if context was cancelled.
+// ctx.Done() is selected together with signals, so waits do not spawn goroutines regardless of how often they are cancelled.
 func (c *RWCond) WaitWithContext(ctx context.Context) (bool, error) {
-	return wake.UnsafeWaitContext(c.r, c.rwl, ctx)
+	return c.waitWithContext(c.rwl, ctx)
+}
+
+// WaitWithContextCause is same as [RWCond.WaitWithContext], but returns context.Cause(ctx) instead of ctx.Err(),
+// e.g. the cause passed to the cancel function of context.WithCancelCause. Without a cause it is same as ctx.Err().
+func (c *RWCond) WaitWithContextCause(ctx context.Context) (bool, error) {
+	return c.waitWithContextCause(c.rwl, ctx)
+}
+
+// WaitWithContextEx is same as [RWCond.WaitWithContext], but also reports if the goroutine was parked.
+// parked is false if RWCond was already closed or ctx was already cancelled. In this case locker is never RUnlocked.
+func (c *RWCond) WaitWithContextEx(ctx context.Context) (woken bool, parked bool, err error) {
+	return c.waitWithContextEx(c.rwl, ctx)
+}
+
+// WaitWithContexts is same as [RWCond.WaitWithContext], but unblocks when any of ctxs is cancelled and returns its error.
+// If several contexts are already cancelled on call, the first one in argument order is reported and locker is never RUnlocked.
+func (c *RWCond) WaitWithContexts(ctxs ...context.Context) (bool, error) {
+	return c.waitWithContexts(c.rwl, ctxs)
+}
+
+// WaitUpgrade RUnlocks locker, blocks until awaken (returns true) or RWCond was closed (returns false), and at the end Locks locker for writing.
+// The caller must hold the read lock and holds the write lock after return. If several readers upgrade at once,
+// each of them releases its read lock before parking, so they queue on Lock one at a time instead of deadlocking.
+func (c *RWCond) WaitUpgrade() bool {
+	c.upgraders.Add(1)
+	defer c.upgraders.Add(-1)
+
+	l := &upgradeLocker{mtx: c.L, writers: &c.writers}
+	ok := c.park(l)
+	// park returns without touching locker if RWCond is closed.
+	if !l.unlocked {
+		c.L.RUnlock()
+		c.L.Lock()
+	}
+	return ok
+}
+
+// WaitUpgradeFor waits as a reader until pred holds and returns holding the write lock ("wait as reader, act as writer").
+// The caller must hold the read lock and holds the write lock after return. pred is first called with the read lock held,
+// and always re-checked with the write lock held, because another writer may change state while the read lock is upgraded.
+// Returns true if pred holds under the write lock, or false if RWCond was closed.
+func (c *RWCond) WaitUpgradeFor(pred func() bool) bool {
+	pred = c.predicate("WaitUpgradeFor", pred)
+	if pred() {
+		c.L.RUnlock()
+		c.L.Lock()
+	} else if !c.WaitUpgrade() {
+		return false
+	}
+	for !pred() {
+		if !c.park(writeLocker{mtx: c.L, writers: &c.writers}) {
+			return false
+		}
+	}
+	return true
+}
+
+// UpgradeWaiters returns current number of goroutines in [RWCond.WaitUpgrade] (parked or waiting for the write lock).
+func (c *RWCond) UpgradeWaiters() int {
+	return int(c.upgraders.Load())
+}
+
+// BroadcastAndSet Locks locker, runs set to update shared state, broadcasts and Unlocks locker. Awoken goroutines re-acquire
+// locker only after set returned, so they always observe the change. Reports how many goroutines were waiting (see [commonCond.Signal]).
+// It acquires locker itself, so it must be called without holding it.
+func (c *RWCond) BroadcastAndSet(set func()) int {
+	return c.broadcastAndSet(c.L, set)
+}
+
+// WaitCountReaders returns current number of goroutines waiting for signal as readers (Wait, WaitWithContext, WaitFor, ...).
+// It equals WaitCount() - WaitCountWriters() (never negative).
+func (c *RWCond) WaitCountReaders() int {
+	return max(c.WaitCount()-c.WaitCountWriters(), 0)
+}
+
+// WaitCountWriters returns current number of goroutines waiting for signal to acquire the write lock
+// ([RWCond.WaitUpgrade] and [RWCond.WaitUpgradeFor]). Unlike UpgradeWaiters, goroutines waiting for the write lock itself are not counted.
+func (c *RWCond) WaitCountWriters() int {
+	return int(c.writers.Load())
+}
+
+// upgradeLocker RUnlocks and Locks for writing. writers counts goroutines, which are parked between Unlock and Lock.
+type upgradeLocker struct {
+	mtx      *sync.RWMutex
+	writers  *atomic.Int64
+	unlocked bool
+}
+
+func (l *upgradeLocker) Lock() {
+	l.writers.Add(-1)
+	l.mtx.Lock()
+}
+
+func (l *upgradeLocker) Unlock() {
+	l.unlocked = true
+	l.writers.Add(1)
+	l.mtx.RUnlock()
+}
+
+// writeLocker is same as upgradeLocker, but Unlocks for writing.
+type writeLocker struct {
+	mtx     *sync.RWMutex
+	writers *atomic.Int64
+}
+
+func (l writeLocker) Lock() {
+	l.writers.Add(-1)
+	l.mtx.Lock()
+}
+
+func (l writeLocker) Unlock() {
+	l.writers.Add(1)
+	l.mtx.Unlock()
 }
 
 type rlocker struct {
@@ -130,14 +632,12 @@ func (l rlocker) Unlock() {
 }
 
 // NewRW returns RWCond with associated sync.RWMutex. Uses RUnlock and RLock for Wait and WaitWithContext methods. Other methods do not use associated sync.RWMutex.
-func NewRW(l *sync.RWMutex) *RWCond {
-	s, r := wake.New()
-	return &RWCond{
+// opts enable optional behavior, see [Option]. Like [New], RWCond created inside a testing/synctest bubble is durably blocking.
+func NewRW(l *sync.RWMutex, opts ...Option) *RWCond {
+	c := &RWCond{
 		L:   l,
 		rwl: rlocker{mtx: l},
-		commonCond: commonCond{
-			s: s,
-			r: r,
-		},
 	}
+	c.init(opts)
+	return c
 }
@@ -0,0 +1,54 @@
+// Package condotel reports stats of [cond.Cond] and [cond.RWCond] as OpenTelemetry observable gauges.
+// It is a separate module (github.com/nursik/go-cond/condotel), so importers of the cond package do not depend on OpenTelemetry.
+package condotel
+
+import (
+	"context"
+
+	"github.com/nursik/go-cond"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Source is implemented by *cond.Cond and *cond.RWCond.
+type Source interface {
+	Stats() cond.Stats
+}
+
+// Register creates observable gauges "cond.waiting" and "cond.closed" on meter, which report stats of conds
+// with the "cond" attribute set to the map key. conds is copied. The callback only reads atomics.
+// Call Unregister on the returned registration to stop reporting.
+func Register(meter metric.Meter, conds map[string]Source) (metric.Registration, error) {
+	waiting, err := meter.Int64ObservableGauge("cond.waiting",
+		metric.WithDescription("Number of goroutines waiting for signal."))
+	if err != nil {
+		return nil, err
+	}
+	closed, err := meter.Int64ObservableGauge("cond.closed",
+		metric.WithDescription("1 if cond is closed, 0 otherwise."))
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		src   Source
+		attrs metric.MeasurementOption
+	}
+	entries := make([]entry, 0, len(conds))
+	for name, src := range conds {
+		entries = append(entries, entry{src, metric.WithAttributes(attribute.String("cond", name))})
+	}
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, e := range entries {
+			st := e.src.Stats()
+			var c int64
+			if st.Closed {
+				c = 1
+			}
+			o.ObserveInt64(waiting, int64(st.Waiting), e.attrs)
+			o.ObserveInt64(closed, c, e.attrs)
+		}
+		return nil
+	}, waiting, closed)
+}
@@ -0,0 +1,62 @@
+package condotel_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/nursik/go-cond"
+	"github.com/nursik/go-cond/condotel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegister(t *testing.T) {
+	a := cond.New(&sync.Mutex{})
+	b := cond.NewRW(&sync.RWMutex{})
+	defer a.Close()
+	b.Close()
+
+	parked, _ := a.WaitReady()
+	<-parked
+
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	reg, err := condotel.Register(meter, map[string]condotel.Source{"a": a, "b": b})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]map[string]int64{
+		"cond.waiting": {"a": 1, "b": 0},
+		"cond.closed":  {"a": 0, "b": 1},
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	seen := 0
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			gauge, ok := m.Data.(metricdata.Gauge[int64])
+			if !ok {
+				t.Fatalf("%s: want int64 gauge, got %T", m.Name, m.Data)
+			}
+			for _, dp := range gauge.DataPoints {
+				name, _ := dp.Attributes.Value(attribute.Key("cond"))
+				if got := dp.Value; got != want[m.Name][name.AsString()] {
+					t.Errorf("%s{cond=%s}: want %d, got %d", m.Name, name.AsString(), want[m.Name][name.AsString()], got)
+				}
+				seen++
+			}
+		}
+	}
+	if seen != 4 {
+		t.Fatalf("want 4 data points, got %d", seen)
+	}
+
+	if err := reg.Unregister(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,22 @@
+package condotel_test
+
+import (
+	"sync"
+
+	"github.com/nursik/go-cond"
+	"github.com/nursik/go-cond/condotel"
+	"go.opentelemetry.io/otel"
+)
+
+func ExampleRegister() {
+	queue := cond.New(&sync.Mutex{})
+	defer queue.Close()
+
+	// Use the global meter provider, which is configured by the application (e.g. with an OTLP exporter).
+	meter := otel.Meter("app")
+	reg, err := condotel.Register(meter, map[string]condotel.Source{"queue": queue})
+	if err != nil {
+		panic(err)
+	}
+	defer reg.Unregister()
+}
@@ -0,0 +1,25 @@
+module github.com/nursik/go-cond/condotel
+
+go 1.22.0
+
+require (
+	github.com/nursik/go-cond v0.0.0-20261015100917-43ff95f4fb82
+	go.opentelemetry.io/otel v1.33.0
+	go.opentelemetry.io/otel/metric v1.33.0
+	go.opentelemetry.io/otel/sdk/metric v1.33.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/nursik/wake v0.4.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/sdk v1.33.0 // indirect
+	go.opentelemetry.io/otel/trace v1.33.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
+
+// Builds inside this repository use the working tree. Importers of this module ignore replace directives
+// and resolve the version required above.
+replace github.com/nursik/go-cond => ../
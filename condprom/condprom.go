@@ -0,0 +1,80 @@
+// Package condprom exports stats of [cond.Cond] and [cond.RWCond] as Prometheus metrics.
+// It is a separate module (github.com/nursik/go-cond/condprom), so importers of the cond package do not depend on Prometheus.
+package condprom
+
+import (
+	"sync"
+
+	"github.com/nursik/go-cond"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Source is implemented by *cond.Cond and *cond.RWCond.
+type Source interface {
+	Stats() cond.Stats
+}
+
+// Collector is a prometheus.Collector, which reports stats of named Conds with the "cond" label. All methods are thread safe.
+type Collector struct {
+	mu    sync.RWMutex
+	conds map[string]Source
+
+	waiting    *prometheus.Desc
+	closed     *prometheus.Desc
+	signalled  *prometheus.Desc
+	broadcasts *prometheus.Desc
+}
+
+// NewCollector returns Collector with metrics prefixed by namespace (may be empty).
+func NewCollector(namespace string) *Collector {
+	name := func(n string) string {
+		return prometheus.BuildFQName(namespace, "cond", n)
+	}
+	labels := []string{"cond"}
+	return &Collector{
+		conds:      make(map[string]Source),
+		waiting:    prometheus.NewDesc(name("waiting"), "Number of goroutines waiting for signal.", labels, nil),
+		closed:     prometheus.NewDesc(name("closed"), "1 if cond is closed, 0 otherwise.", labels, nil),
+		signalled:  prometheus.NewDesc(name("signalled_total"), "Total number of goroutines awoken by signal.", labels, nil),
+		broadcasts: prometheus.NewDesc(name("broadcasts_total"), "Total number of broadcasts.", labels, nil),
+	}
+}
+
+// Add registers s under name. Adding under an existing name replaces the previous source.
+func (c *Collector) Add(name string, s Source) {
+	c.mu.Lock()
+	c.conds[name] = s
+	c.mu.Unlock()
+}
+
+// Remove unregisters the source with name.
+func (c *Collector) Remove(name string) {
+	c.mu.Lock()
+	delete(c.conds, name)
+	c.mu.Unlock()
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.waiting
+	ch <- c.closed
+	ch <- c.signalled
+	ch <- c.broadcasts
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, s := range c.conds {
+		st := s.Stats()
+		var closed float64
+		if st.Closed {
+			closed = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.waiting, prometheus.GaugeValue, float64(st.Waiting), name)
+		ch <- prometheus.MustNewConstMetric(c.closed, prometheus.GaugeValue, closed, name)
+		ch <- prometheus.MustNewConstMetric(c.signalled, prometheus.CounterValue, float64(st.Signalled), name)
+		ch <- prometheus.MustNewConstMetric(c.broadcasts, prometheus.CounterValue, float64(st.Broadcasts), name)
+	}
+}
@@ -0,0 +1,41 @@
+package condprom_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nursik/go-cond"
+	"github.com/nursik/go-cond/condprom"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	a := cond.New(&sync.Mutex{})
+	b := cond.NewRW(&sync.RWMutex{})
+	a.Broadcast()
+	b.Close()
+
+	c := condprom.NewCollector("test")
+	c.Add("a", a)
+	c.Add("b", b)
+
+	want := `
+# HELP test_cond_broadcasts_total Total number of broadcasts.
+# TYPE test_cond_broadcasts_total counter
+test_cond_broadcasts_total{cond="a"} 1
+test_cond_broadcasts_total{cond="b"} 0
+# HELP test_cond_closed 1 if cond is closed, 0 otherwise.
+# TYPE test_cond_closed gauge
+test_cond_closed{cond="a"} 0
+test_cond_closed{cond="b"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "test_cond_broadcasts_total", "test_cond_closed"); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Remove("b")
+	if n := testutil.CollectAndCount(c, "test_cond_waiting"); n != 1 {
+		t.Fatalf("want 1 waiting metric, got %d", n)
+	}
+}
@@ -0,0 +1,34 @@
+package condprom_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/nursik/go-cond"
+	"github.com/nursik/go-cond/condprom"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func ExampleCollector() {
+	queue := cond.New(&sync.Mutex{})
+	defer queue.Close()
+
+	collector := condprom.NewCollector("app")
+	collector.Add("queue", queue)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	families, err := registry.Gather()
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range families {
+		fmt.Println(f.GetName())
+	}
+	// Output:
+	// app_cond_broadcasts_total
+	// app_cond_closed
+	// app_cond_signalled_total
+	// app_cond_waiting
+}
@@ -0,0 +1,25 @@
+module github.com/nursik/go-cond/condprom
+
+go 1.22.0
+
+require (
+	github.com/nursik/go-cond v0.0.0-20261015100917-b2bc576df2f6
+	github.com/prometheus/client_golang v1.22.0
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/kylelemons/godebug v1.1.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/nursik/wake v0.4.0 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.62.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
+)
+
+// Builds inside this repository use the working tree. Importers of this module ignore replace directives
+// and resolve the version required above.
+replace github.com/nursik/go-cond => ../
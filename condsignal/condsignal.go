@@ -0,0 +1,53 @@
+// Package condsignal provides waits on [cond.Cond] and [cond.RWCond], which also end on OS signals.
+// It is a separate package, so the cond package stays free of the os/signal dependency.
+package condsignal
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/nursik/go-cond"
+)
+
+// Replaced in tests.
+var (
+	notify = signal.Notify
+	stop   = signal.Stop
+)
+
+// SignalError is returned by [WaitWithSignals], if the wait ended due to an OS signal.
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return "condsignal: received " + e.Signal.String()
+}
+
+// WaitWithSignals calls c.WaitWithContext, which also ends when one of sigs is received (all incoming signals if sigs is empty, see signal.Notify).
+// Returns false and *[SignalError] with the received signal in that case. Otherwise returns the same as WaitWithContext.
+// The caller must hold c's locker as for WaitWithContext. The signal handler is removed with signal.Stop before return.
+func WaitWithSignals(c cond.CondLike, sigs ...os.Signal) (bool, error) {
+	ch := make(chan os.Signal, 1)
+	notify(ch, sigs...)
+	defer stop(ch)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case sig := <-ch:
+			cancel(&SignalError{Signal: sig})
+		case <-done:
+		}
+	}()
+
+	ok, err := c.WaitWithContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		return false, context.Cause(ctx)
+	}
+	return ok, err
+}
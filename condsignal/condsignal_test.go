@@ -0,0 +1,114 @@
+package condsignal
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/nursik/go-cond"
+)
+
+// fakeNotifier replaces signal.Notify and signal.Stop for the duration of a test.
+type fakeNotifier struct {
+	mu      sync.Mutex
+	ch      chan<- os.Signal
+	stopped bool
+}
+
+func newFakeNotifier(t *testing.T) *fakeNotifier {
+	f := &fakeNotifier{}
+	notify = func(ch chan<- os.Signal, _ ...os.Signal) {
+		f.mu.Lock()
+		f.ch = ch
+		f.mu.Unlock()
+	}
+	stop = func(chan<- os.Signal) {
+		f.mu.Lock()
+		f.stopped = true
+		f.mu.Unlock()
+	}
+	t.Cleanup(func() {
+		notify, stop = signalNotify, signalStop
+	})
+	return f
+}
+
+var signalNotify, signalStop = notify, stop
+
+func (f *fakeNotifier) send(sig os.Signal) {
+	f.mu.Lock()
+	f.ch <- sig
+	f.mu.Unlock()
+}
+
+func (f *fakeNotifier) isStopped() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stopped
+}
+
+type result struct {
+	ok  bool
+	err error
+}
+
+func wait(c *cond.Cond) <-chan result {
+	ch := make(chan result, 1)
+	go func() {
+		c.L.Lock()
+		ok, err := WaitWithSignals(c, os.Interrupt, syscall.SIGTERM)
+		c.L.Unlock()
+		ch <- result{ok, err}
+	}()
+	for c.WaitCount() < 1 {
+		runtime.Gosched()
+	}
+	return ch
+}
+
+func TestWaitWithSignals(t *testing.T) {
+	f := newFakeNotifier(t)
+	c := cond.New(&sync.Mutex{})
+
+	ch := wait(c)
+	f.send(syscall.SIGTERM)
+	r := <-ch
+	var sigErr *SignalError
+	if r.ok || !errors.As(r.err, &sigErr) || sigErr.Signal != syscall.SIGTERM {
+		t.Fatalf("want false and SIGTERM, got %v and %v", r.ok, r.err)
+	}
+	if !f.isStopped() {
+		t.Fatal("want signal handler to be stopped")
+	}
+}
+
+func TestWaitWithSignalsWoken(t *testing.T) {
+	f := newFakeNotifier(t)
+	c := cond.New(&sync.Mutex{})
+
+	ch := wait(c)
+	c.Signal(1)
+	if r := <-ch; !r.ok || r.err != nil {
+		t.Fatalf("want true and nil, got %v and %v", r.ok, r.err)
+	}
+	if !f.isStopped() {
+		t.Fatal("want signal handler to be stopped")
+	}
+
+	ch = wait(c)
+	c.Close()
+	if r := <-ch; r.ok || r.err != nil {
+		t.Fatalf("want false and nil, got %v and %v", r.ok, r.err)
+	}
+
+	// Errors of WaitWithContext other than cancellation are returned as is.
+	c = cond.New(&sync.Mutex{}, cond.WithErrorOnClose())
+	ch = wait(c)
+	c.Close()
+	if r := <-ch; r.ok || r.err != cond.ErrClosed {
+		t.Fatalf("want false and ErrClosed, got %v and %v", r.ok, r.err)
+	}
+}
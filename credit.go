@@ -0,0 +1,55 @@
+package cond
+
+import "context"
+
+// notice is cancelled to interrupt SignalWithContext calls in flight, when a waiter leaves due to cancellation.
+type notice struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newNotice() *notice {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &notice{ctx: ctx, cancel: cancel}
+}
+
+// creditCancel is called by goroutines, which stopped waiting due to context cancellation.
+func (c *commonCond) creditCancel() {
+	c.cancels.Add(1)
+	if c.inflight.Load() > 0 {
+		if old := c.cancelNotice.Swap(newNotice()); old != nil {
+			old.cancel()
+		}
+	}
+}
+
+// signalCrediting wakes n goroutines like wake's SignalWithContext, but counts waiters, which left due to cancellation
+// during the call, toward n. The notice is loaded before the counter, so a cancellation after the counter was read always
+// interrupts the current round.
+func (c *commonCond) signalCrediting(ctx context.Context, n int) (int, error) {
+	c.inflight.Add(1)
+	defer c.inflight.Add(-1)
+	if c.cancelNotice.Load() == nil {
+		c.cancelNotice.CompareAndSwap(nil, newNotice())
+	}
+	total := 0
+	for n > 0 {
+		nt := c.cancelNotice.Load()
+		start := c.cancels.Load()
+		round, cancel := context.WithCancel(ctx)
+		stop := context.AfterFunc(nt.ctx, cancel)
+		x, err := c.s.SignalWithContext(round, n)
+		stop()
+		cancel()
+		total += x
+		n -= x
+		if err != nil && ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		if c.s.IsClosed() {
+			return total, nil
+		}
+		n -= int(min(c.cancels.Load()-start, uint64(n)))
+	}
+	return total, nil
+}
@@ -0,0 +1,37 @@
+package cond
+
+import (
+	"log"
+	"time"
+)
+
+func logDeadlock(waiting int) {
+	log.Printf("cond: possible deadlock: %d goroutines are waiting without signals", waiting)
+}
+
+// detectDeadlock calls handler once WaitCount() > 0 has not changed and there were no signals/broadcasts for timeout.
+// It reports each stuck state once and exits on Close.
+func (c *commonCond) detectDeadlock(timeout time.Duration, handler func(waiting int)) {
+	t := time.NewTicker(timeout)
+	defer t.Stop()
+
+	last := c.Stats()
+	reported := false
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-t.C:
+		}
+		st := c.Stats()
+		if st.Waiting > 0 && st.Waiting == last.Waiting && st.Signalled == last.Signalled && st.Broadcasts == last.Broadcasts {
+			if !reported {
+				reported = true
+				handler(st.Waiting)
+			}
+			continue
+		}
+		last = st
+		reported = false
+	}
+}
@@ -0,0 +1,50 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWithDeadlockDetection(t *testing.T) {
+	reports := make(chan int, 10)
+	c := New(&sync.Mutex{}, WithDeadlockDetection(5*time.Millisecond, func(waiting int) {
+		reports <- waiting
+	}))
+	defer c.Close()
+
+	// No waiters, no reports.
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case n := <-reports:
+		t.Fatalf("unexpected report with %d waiters", n)
+	default:
+	}
+
+	n := 3
+	for i := 0; i < n; i++ {
+		go func() {
+			c.L.Lock()
+			c.Wait()
+			c.L.Unlock()
+		}()
+	}
+	select {
+	case got := <-reports:
+		if got != n {
+			t.Fatalf("want %d waiting, got %d", n, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("deadlock was not reported")
+	}
+
+	// Same stuck state is reported once.
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-reports:
+		t.Fatal("stuck state was reported twice")
+	default:
+	}
+}
@@ -0,0 +1,79 @@
+package cond
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxStackDepth limits frames captured by [WithStackCapture].
+const maxStackDepth = 16
+
+// waiter is a goroutine parked on Cond/RWCond tracked by [WithStackCapture].
+type waiter struct {
+	parked time.Time
+	pcs    []uintptr
+}
+
+func (c *commonCond) track() *waiter {
+	pcs := make([]uintptr, maxStackDepth)
+	// Skip runtime.Callers, track and parkLocker.Unlock.
+	pcs = pcs[:runtime.Callers(3, pcs)]
+	w := &waiter{parked: time.Now(), pcs: pcs}
+	c.waitersMu.Lock()
+	if c.waiters == nil {
+		c.waiters = make(map[*waiter]struct{})
+	}
+	c.waiters[w] = struct{}{}
+	c.waitersMu.Unlock()
+	return w
+}
+
+func (c *commonCond) untrack(w *waiter) {
+	c.waitersMu.Lock()
+	delete(c.waiters, w)
+	c.waitersMu.Unlock()
+}
+
+// DumpWaiters returns a human-readable summary of parked goroutines for debugging hangs.
+// With [WithStackCapture] it lists every parked goroutine, longest parked first, with its park time and the stack of its Wait call
+// (frames of this package and wake are omitted). Without the option it only reports WaitCount().
+func (c *commonCond) DumpWaiters() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "cond: %d goroutines waiting\n", c.WaitCount())
+	if !c.opts.stackCapture {
+		return b.String()
+	}
+
+	c.waitersMu.Lock()
+	waiters := make([]*waiter, 0, len(c.waiters))
+	for w := range c.waiters {
+		waiters = append(waiters, w)
+	}
+	c.waitersMu.Unlock()
+	slices.SortFunc(waiters, func(a, b *waiter) int {
+		return a.parked.Compare(b.parked)
+	})
+
+	now := time.Now()
+	for _, w := range waiters {
+		fmt.Fprintf(&b, "parked for %v:\n", now.Sub(w.parked).Round(time.Millisecond))
+		frames := runtime.CallersFrames(w.pcs)
+		for {
+			f, more := frames.Next()
+			if !internalFrame(f.Function) {
+				fmt.Fprintf(&b, "\t%s\n\t\t%s:%d\n", f.Function, f.File, f.Line)
+			}
+			if !more {
+				break
+			}
+		}
+	}
+	return b.String()
+}
+
+func internalFrame(fn string) bool {
+	return strings.HasPrefix(fn, "github.com/nursik/go-cond.") || strings.HasPrefix(fn, "github.com/nursik/wake.")
+}
@@ -0,0 +1,59 @@
+package cond_test
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+func parkForDump(c *Cond, wg *sync.WaitGroup) {
+	defer wg.Done()
+	c.L.Lock()
+	c.Wait()
+	c.L.Unlock()
+}
+
+func TestDumpWaiters(t *testing.T) {
+	c := New(&sync.Mutex{}, WithStackCapture())
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go parkForDump(c, &wg)
+	}
+	waitParked(c, 2)
+	// The registry is updated right after the goroutine is counted.
+	dump := c.DumpWaiters()
+	for strings.Count(dump, "parked for") < 2 {
+		runtime.Gosched()
+		dump = c.DumpWaiters()
+	}
+	if !strings.HasPrefix(dump, "cond: 2 goroutines waiting\n") {
+		t.Fatalf("unexpected header:\n%s", dump)
+	}
+	if strings.Count(dump, "go-cond_test.parkForDump") != 2 {
+		t.Fatalf("want both parked goroutines with their stacks:\n%s", dump)
+	}
+	if strings.Contains(dump, "nursik/wake.") || strings.Contains(dump, "go-cond.(*") {
+		t.Fatalf("want internal frames omitted:\n%s", dump)
+	}
+
+	c.Broadcast()
+	wg.Wait()
+	if dump := c.DumpWaiters(); dump != "cond: 0 goroutines waiting\n" {
+		t.Fatalf("want no waiters, got:\n%s", dump)
+	}
+}
+
+func TestDumpWaitersWithoutCapture(t *testing.T) {
+	c := New(&sync.Mutex{})
+	parked, result := c.WaitReady()
+	<-parked
+	if dump := c.DumpWaiters(); dump != "cond: 1 goroutines waiting\n" {
+		t.Fatalf("want only count, got:\n%s", dump)
+	}
+	c.Signal(1)
+	<-result
+}
@@ -0,0 +1,30 @@
+package cond
+
+// SignalEpoch is same as [commonCond.Signal] with n > 0, but also assigns the call a unique monotonic epoch (starting at 1),
+// which goroutines woken by it report via WaitEpoch, e.g. to correlate a signal with its wakes in logs.
+// Attribution is best-effort: a woken goroutine reports the epoch only if no other signal or broadcast was delivered
+// before it read the epoch, otherwise it reports 0. SignalEpoch does not wait for woken goroutines.
+// If n <= 0, it wakes nobody and returns 0 and 0.
+func (c *commonCond) SignalEpoch(n int) (woken int, epoch uint64) {
+	if n <= 0 {
+		return 0, 0
+	}
+	c.epochMu.Lock()
+	defer c.epochMu.Unlock()
+	c.epochs++
+	epoch = c.epochs
+	c.epoch.Store(epoch)
+	// Signal increments the sequence once it is delivered.
+	c.epochSeq.Store(c.seq.Load() + 1)
+	return c.Signal(n), epoch
+}
+
+// wokenEpoch is called by awoken goroutines and reports the epoch of the latest SignalEpoch,
+// if no other signal was delivered since, or 0.
+func (c *commonCond) wokenEpoch() uint64 {
+	epoch := c.epoch.Load()
+	if c.seq.Load() != c.epochSeq.Load() {
+		return 0
+	}
+	return epoch
+}
@@ -0,0 +1,14 @@
+package cond
+
+import "errors"
+
+var (
+	// ErrClosed is returned by methods, which report use of closed Cond/RWCond.
+	ErrClosed = errors.New("cond: closed")
+	// ErrBudgetExceeded is returned by WaitForBudget methods, if predicate is still false after maxWakes wakes.
+	ErrBudgetExceeded = errors.New("cond: wake budget exceeded")
+	// ErrWaitLimit is returned by Wait methods with context, if [WaitLimiter] set by [WithWaitLimiter] has no free slots.
+	ErrWaitLimit = errors.New("cond: wait limit reached")
+	// ErrLockerMismatch is returned by [NewCondGroup], if Conds do not share the same locker.
+	ErrLockerMismatch = errors.New("cond: conds do not share the same locker")
+)
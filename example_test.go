@@ -0,0 +1,196 @@
+package cond_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/bits"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nursik/go-cond"
+)
+
+func ExampleWithBroadcastObserver() {
+	// herd[i] counts broadcasts, which woke from 2^(i-1) to 2^i-1 goroutines.
+	var herd [8]int
+	var mu sync.Mutex
+	c := cond.New(&sync.Mutex{}, cond.WithBroadcastObserver(func(woken int) {
+		mu.Lock()
+		herd[bits.Len(uint(woken))]++
+		mu.Unlock()
+	}))
+
+	var wg sync.WaitGroup
+	for _, waiters := range []int{0, 1, 3, 5} {
+		for i := 0; i < waiters; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.L.Lock()
+				c.Wait()
+				c.L.Unlock()
+			}()
+		}
+		for c.WaitCount() < waiters {
+			runtime.Gosched()
+		}
+		c.Broadcast()
+		wg.Wait()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Println(herd[:4])
+	// Output: [1 1 1 1]
+}
+
+func ExampleWithScalePolicy() {
+	// Clients wait until a worker serves them. The pool wants one worker per two waiting clients, 1 to 4 workers.
+	c := cond.New(&sync.Mutex{}, cond.WithScalePolicy(1, 4, func(waiting int) int {
+		return (waiting + 1) / 2
+	}))
+	defer c.Close()
+
+	var queue []int
+	served := make(map[int]bool)
+	var clients sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		clients.Add(1)
+		go func() {
+			defer clients.Done()
+			c.L.Lock()
+			queue = append(queue, i)
+			c.WaitFor(func() bool { return served[i] })
+			c.L.Unlock()
+		}()
+	}
+	for c.WaitCount() < 6 || c.ScaleTarget() != 3 {
+		runtime.Gosched()
+	}
+
+	workers := c.ScaleTarget()
+	fmt.Println("workers:", workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				c.L.Lock()
+				if len(queue) == 0 {
+					c.L.Unlock()
+					return
+				}
+				served[queue[0]] = true
+				queue = queue[1:]
+				c.L.Unlock()
+				c.Broadcast()
+			}
+		}()
+	}
+	clients.Wait()
+	fmt.Println("served:", len(served))
+	// Output:
+	// workers: 3
+	// served: 6
+}
+
+func ExampleWithPprofLabels() {
+	c := cond.New(&sync.Mutex{}, cond.WithPprofLabels(map[string]string{"cond": "orders"}))
+	go func() {
+		c.L.Lock()
+		c.WaitWithContext(context.Background())
+		c.L.Unlock()
+	}()
+	for c.WaitCount() < 1 {
+		runtime.Gosched()
+	}
+
+	var buf bytes.Buffer
+	pprof.Lookup("goroutine").WriteTo(&buf, 1)
+	fmt.Println(strings.Contains(buf.String(), `labels: {"cond":"orders"}`))
+	c.Close()
+	// Output: true
+}
+
+func ExampleCondGroup() {
+	// Bounded queue: producers wait on notFull, consumers - on notEmpty. Closing wakes both sides at once.
+	var mu sync.Mutex
+	notFull, notEmpty := cond.New(&mu), cond.New(&mu)
+	g, _ := cond.NewCondGroup(notFull, notEmpty)
+
+	const capacity = 2
+	var items []int
+	closed := false
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			g.L.Lock()
+			notFull.WaitFor(func() bool { return closed || len(items) < capacity })
+			if closed {
+				g.L.Unlock()
+				return
+			}
+			items = append(items, i)
+			g.L.Unlock()
+			notEmpty.Signal(1)
+		}
+	}()
+	sum := 0
+	go func() {
+		defer wg.Done()
+		for {
+			g.L.Lock()
+			notEmpty.WaitFor(func() bool { return closed || len(items) > 0 })
+			if closed {
+				g.L.Unlock()
+				return
+			}
+			sum += items[0]
+			items = items[1:]
+			done := sum >= 10
+			g.L.Unlock()
+			notFull.Signal(1)
+			if done {
+				break
+			}
+		}
+		// Stop the producer, which may be waiting for space.
+		g.L.Lock()
+		closed = true
+		g.L.Unlock()
+		g.SignalAll()
+	}()
+	wg.Wait()
+	fmt.Println(sum)
+	// Output: 10
+}
+
+func ExampleWithSpuriousWakeups() {
+	// Every second wait ends without a signal, so a consumer, which does not re-check its predicate, would proceed too early.
+	var mu sync.Mutex
+	c := cond.New(&mu, cond.WithSpuriousWakeups(0.5))
+	ready := false
+
+	done := make(chan bool)
+	go func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for !ready {
+			c.Wait()
+		}
+		done <- ready
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	ready = true
+	mu.Unlock()
+	c.Signal(1)
+	fmt.Println(<-done)
+	// Output: true
+}
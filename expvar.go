@@ -0,0 +1,44 @@
+package cond
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
+
+// expvarRegistry maps names published by WithExpvar to the Cond/RWCond, which is currently reported under the name.
+// expvar can not unpublish, so a name is published once and then re-pointed.
+var expvarRegistry struct {
+	mu    sync.Mutex
+	conds map[string]*atomic.Pointer[commonCond]
+}
+
+// publish reports c under name. If name is already published by another Cond/RWCond, c replaces it.
+// If name is taken by a variable, which was not published by this package, publish does nothing.
+func (c *commonCond) publish(name string) {
+	expvarRegistry.mu.Lock()
+	defer expvarRegistry.mu.Unlock()
+	p, ok := expvarRegistry.conds[name]
+	if !ok {
+		if expvar.Get(name) != nil {
+			return
+		}
+		p = new(atomic.Pointer[commonCond])
+		stat := func(f func(s Stats) any) expvar.Func {
+			return func() any {
+				return f(p.Load().Stats())
+			}
+		}
+		m := new(expvar.Map)
+		m.Set("Waiting", stat(func(s Stats) any { return s.Waiting }))
+		m.Set("Closed", stat(func(s Stats) any { return s.Closed }))
+		m.Set("Signalled", stat(func(s Stats) any { return s.Signalled }))
+		m.Set("Broadcasts", stat(func(s Stats) any { return s.Broadcasts }))
+		expvar.Publish(name, m)
+		if expvarRegistry.conds == nil {
+			expvarRegistry.conds = make(map[string]*atomic.Pointer[commonCond])
+		}
+		expvarRegistry.conds[name] = p
+	}
+	p.Store(c)
+}
@@ -0,0 +1,63 @@
+package cond_test
+
+import (
+	"expvar"
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+func expvarStat(t *testing.T, name, key string) string {
+	t.Helper()
+	m, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		t.Fatalf("%s is not published as expvar.Map", name)
+	}
+	v := m.Get(key)
+	if v == nil {
+		t.Fatalf("%s has no key %s", name, key)
+	}
+	return v.String()
+}
+
+func TestWithExpvar(t *testing.T) {
+	c := New(&sync.Mutex{}, WithExpvar("cond_test_expvar"))
+	parked, result := c.WaitReady()
+	<-parked
+	if v := expvarStat(t, "cond_test_expvar", "Waiting"); v != "1" {
+		t.Fatalf("want Waiting 1, got %s", v)
+	}
+	c.Broadcast()
+	<-result
+	if v := expvarStat(t, "cond_test_expvar", "Broadcasts"); v != "1" {
+		t.Fatalf("want Broadcasts 1, got %s", v)
+	}
+	if v := expvarStat(t, "cond_test_expvar", "Closed"); v != "false" {
+		t.Fatalf("want Closed false, got %s", v)
+	}
+
+	// Publishing the same name again does not panic and reports the new Cond.
+	c.Close()
+	c2 := New(&sync.Mutex{}, WithExpvar("cond_test_expvar"))
+	defer c2.Close()
+	if v := expvarStat(t, "cond_test_expvar", "Closed"); v != "false" {
+		t.Fatalf("want Closed of the new Cond, got %s", v)
+	}
+	if v := expvarStat(t, "cond_test_expvar", "Broadcasts"); v != "0" {
+		t.Fatalf("want Broadcasts of the new Cond, got %s", v)
+	}
+}
+
+func TestWithExpvarTaken(t *testing.T) {
+	// Published once per test binary, so the test can be re-run with -count.
+	v := expvar.Get("cond_test_expvar_taken")
+	if v == nil {
+		v = expvar.NewInt("cond_test_expvar_taken")
+	}
+	c := New(&sync.Mutex{}, WithExpvar("cond_test_expvar_taken"))
+	defer c.Close()
+	if expvar.Get("cond_test_expvar_taken") != v {
+		t.Fatal("foreign variable was replaced")
+	}
+}
@@ -0,0 +1,77 @@
+package cond
+
+import (
+	"context"
+	"sync"
+)
+
+// Gather is a scatter-gather primitive: workers Contribute values and wait until a coordinator Collects them.
+// All methods are thread safe.
+type Gather[T any] struct {
+	mu sync.Mutex
+	// collector is signalled on contributions, contributors - on collections.
+	collector    *Cond
+	contributors *Cond
+	values       []T
+	contributed  uint64
+	collected    uint64
+}
+
+// NewGather returns empty Gather.
+func NewGather[T any]() *Gather[T] {
+	g := &Gather[T]{}
+	g.collector = New(&g.mu)
+	g.contributors = New(&g.mu)
+	return g
+}
+
+// Contribute adds v and blocks until v is taken by [Gather.Collect] (returns true) or Gather was closed (returns false).
+func (g *Gather[T]) Contribute(v T) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.contributors.IsClosed() {
+		return false
+	}
+	seq := g.contributed
+	g.contributed++
+	g.values = append(g.values, v)
+	g.collector.Broadcast()
+	return g.contributors.WaitFor(func() bool { return g.collected > seq })
+}
+
+// Collect blocks until expected values are contributed, takes them in contribution order and releases their contributors.
+// If ctx is cancelled first, it takes values contributed so far and returns them with ctx.Err().
+// If Gather was closed, it returns values contributed so far and [ErrClosed].
+func (g *Gather[T]) Collect(ctx context.Context, expected int) ([]T, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var err error
+	for len(g.values) < expected {
+		ok, werr := g.collector.WaitWithContext(ctx)
+		if werr != nil {
+			err = werr
+			break
+		}
+		if !ok {
+			err = ErrClosed
+			break
+		}
+	}
+	n := min(len(g.values), expected)
+	values := make([]T, n)
+	copy(values, g.values)
+	g.values = append(g.values[:0], g.values[n:]...)
+	g.collected += uint64(n)
+	g.contributors.Broadcast()
+	return values, err
+}
+
+// Close closes Gather and drops values, which were not collected. Blocked and subsequent Contribute calls return false,
+// and Collect returns [ErrClosed]. The first Close returns true.
+func (g *Gather[T]) Close() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values = nil
+	g.collector.Close()
+	return g.contributors.Close()
+}
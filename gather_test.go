@@ -0,0 +1,100 @@
+package cond_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestGather(t *testing.T) {
+	g := NewGather[int]()
+	n := 100
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if !g.Contribute(i) {
+				t.Error("want true for collected contribution")
+			}
+		}(i)
+	}
+	values, err := g.Collect(context.Background(), n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	slices.Sort(values)
+	for i, v := range values {
+		if v != i {
+			t.Fatalf("want values 0..%d, got %v", n-1, values)
+		}
+	}
+}
+
+func TestGatherRounds(t *testing.T) {
+	g := NewGather[int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			g.Contribute(i)
+		}(i)
+	}
+	for round := 0; round < 3; round++ {
+		values, err := g.Collect(context.Background(), 2)
+		if err != nil || len(values) != 2 {
+			t.Fatalf("round %d: want 2 values and nil, got %v and %v", round, values, err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestGatherCollectCancelled(t *testing.T) {
+	g := NewGather[int]()
+	n := 3
+	released := make(chan bool, n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			released <- g.Contribute(i)
+		}(i)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	values, err := g.Collect(ctx, n+2)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("want context.DeadlineExceeded, got %v", err)
+	}
+	if len(values) != n {
+		t.Fatalf("want %d partial values, got %v", n, values)
+	}
+	for i := 0; i < n; i++ {
+		if !<-released {
+			t.Fatal("want partial contributors to be released with true")
+		}
+	}
+}
+
+func TestGatherClose(t *testing.T) {
+	g := NewGather[string]()
+	released := make(chan bool)
+	go func() {
+		released <- g.Contribute("x")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	g.Close()
+	if <-released {
+		t.Fatal("want false for closed Gather")
+	}
+	if g.Contribute("y") {
+		t.Fatal("want false for closed Gather")
+	}
+	if _, err := g.Collect(context.Background(), 1); err != ErrClosed {
+		t.Fatalf("want ErrClosed, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package cond
+
+import "sync"
+
+// CondGroup is a set of Conds sharing one locker, e.g. "not full" and "not empty" conditions of a bounded queue.
+// All methods are thread safe.
+type CondGroup struct {
+	// L is the locker shared by all Conds of the group.
+	L     sync.Locker
+	conds []*Cond
+}
+
+// NewCondGroup returns CondGroup of conds. It returns [ErrLockerMismatch], if conds do not share the same locker, or no conds are given.
+func NewCondGroup(conds ...*Cond) (*CondGroup, error) {
+	if len(conds) == 0 {
+		return nil, ErrLockerMismatch
+	}
+	for _, c := range conds[1:] {
+		if c.L != conds[0].L {
+			return nil, ErrLockerMismatch
+		}
+	}
+	return &CondGroup{L: conds[0].L, conds: append([]*Cond(nil), conds...)}, nil
+}
+
+// Conds returns Conds of the group in the order they were given to [NewCondGroup].
+func (g *CondGroup) Conds() []*Cond {
+	return append([]*Cond(nil), g.conds...)
+}
+
+// SignalAll broadcasts all Conds of the group while holding L and reports how many goroutines were waiting on them.
+// Awoken goroutines re-check their conditions only after all Conds were broadcast. Must not be called with L held.
+func (g *CondGroup) SignalAll() int {
+	g.L.Lock()
+	defer g.L.Unlock()
+	n := 0
+	for _, c := range g.conds {
+		n += c.broadcast()
+	}
+	return n
+}
+
+// Close closes all Conds of the group and reports if any of them was closed by this call.
+func (g *CondGroup) Close() bool {
+	first := false
+	for _, c := range g.conds {
+		if c.Close() {
+			first = true
+		}
+	}
+	return first
+}
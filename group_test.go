@@ -0,0 +1,68 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestNewCondGroupLockerMismatch(t *testing.T) {
+	var mu sync.Mutex
+	if _, err := NewCondGroup(New(&mu), New(&sync.Mutex{})); err != ErrLockerMismatch {
+		t.Fatalf("want ErrLockerMismatch, got %v", err)
+	}
+	if _, err := NewCondGroup(); err != ErrLockerMismatch {
+		t.Fatalf("want ErrLockerMismatch for empty group, got %v", err)
+	}
+	g, err := NewCondGroup(New(&mu), New(&mu))
+	if err != nil || g.L != &mu || len(g.Conds()) != 2 {
+		t.Fatal("want group sharing mu")
+	}
+}
+
+func TestCondGroupSignalAll(t *testing.T) {
+	var mu sync.Mutex
+	a, b := New(&mu), New(&mu)
+	g, err := NewCondGroup(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for _, c := range []*Cond{a, a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			c.Wait()
+			mu.Unlock()
+		}()
+	}
+	waitParked(a, 2)
+	waitParked(b, 1)
+
+	// SignalAll holds the shared locker, so it waits for the holder.
+	mu.Lock()
+	done := make(chan int)
+	go func() {
+		done <- g.SignalAll()
+	}()
+	select {
+	case <-done:
+		t.Fatal("SignalAll returned without the shared locker")
+	case <-time.After(10 * time.Millisecond):
+	}
+	mu.Unlock()
+	if n := <-done; n != 3 {
+		t.Fatalf("want 3, got %d", n)
+	}
+	wg.Wait()
+
+	if !g.Close() || !a.IsClosed() || !b.IsClosed() {
+		t.Fatal("want all conds closed")
+	}
+	if g.Close() {
+		t.Fatal("want false for closed group")
+	}
+}
@@ -0,0 +1,16 @@
+package cond
+
+import "time"
+
+func (c *commonCond) heartbeat(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-t.C:
+			c.broadcastFrom(false)
+		}
+	}
+}
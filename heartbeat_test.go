@@ -0,0 +1,42 @@
+package cond_test
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWithHeartbeat(t *testing.T) {
+	before := runtime.NumGoroutine()
+	c := New(&sync.Mutex{}, WithHeartbeat(time.Millisecond))
+
+	var ready atomic.Bool
+	time.AfterFunc(20*time.Millisecond, func() { ready.Store(true) })
+
+	wakes := 0
+	c.L.Lock()
+	ok := c.WaitFor(func() bool {
+		wakes++
+		return ready.Load()
+	})
+	c.L.Unlock()
+	if !ok {
+		t.Fatal("want true")
+	}
+	if wakes < 2 {
+		t.Fatalf("want periodic wakes, got %d", wakes)
+	}
+
+	c.Close()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatal("heartbeat goroutine leaked")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
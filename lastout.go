@@ -0,0 +1,57 @@
+package cond
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// lastOutLocker counts goroutines parked in WaitLastOut between Unlock and Lock and records, if the goroutine was the last one to leave.
+// Only armed lockers are counted, so paths, which release locker without parking (spurious wakes, waiter queue), are ignored.
+type lastOutLocker struct {
+	l      sync.Locker
+	parked *atomic.Int64
+	armed  bool
+	last   bool
+}
+
+func (l *lastOutLocker) Lock() {
+	if l.armed {
+		l.armed = false
+		l.last = l.parked.Add(-1) == 0
+	}
+	l.l.Lock()
+}
+
+func (l *lastOutLocker) Unlock() {
+	if l.armed {
+		l.parked.Add(1)
+	}
+	l.l.Unlock()
+}
+
+// armLastOut makes l count the goroutine, if l is a lastOutLocker. It is called right before the goroutine parks.
+func armLastOut(l sync.Locker) {
+	if ll, ok := l.(*lastOutLocker); ok {
+		ll.armed = true
+	}
+}
+
+func (c *commonCond) waitLastOut(l sync.Locker) (bool, bool) {
+	ll := &lastOutLocker{l: l, parked: &c.lastOut}
+	ok := c.park(ll)
+	return ok, ll.last
+}
+
+// WaitLastOut is same as [Cond.Wait], but also reports if the goroutine was the last one to leave among goroutines parked in WaitLastOut,
+// e.g. to let the last waiter do teardown. The counter is decremented atomically when the goroutine is awoken (before re-locking locker),
+// so exactly one of goroutines woken together reports wasLast. Waits of other Wait methods are not counted. A goroutine, which starts
+// waiting right after, makes the next departure the last one again, so wasLast means "the waiting set became empty", not "no more waits".
+// wasLast is false, if the goroutine did not park.
+func (c *Cond) WaitLastOut() (woken bool, wasLast bool) {
+	return c.waitLastOut(c.L)
+}
+
+// WaitLastOut is same as [RWCond.Wait], but also reports if the goroutine was the last one to leave (see [Cond.WaitLastOut]).
+func (c *RWCond) WaitLastOut() (woken bool, wasLast bool) {
+	return c.waitLastOut(c.rwl)
+}
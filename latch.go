@@ -0,0 +1,28 @@
+package cond
+
+// BroadcastLatch broadcasts and makes the broadcast sticky: until [commonCond.ClearLatch], all Wait methods return without parking
+// as if awoken by the broadcast. They still release locker, yield and re-acquire it, so predicate loops (WaitFor, WaitUpgradeFor, ...)
+// let other goroutines change the state they wait for. It suits "shutdown requested" flags, which late waiters must observe too.
+// Unlike Close, Cond/RWCond stays usable and the latch can be cleared. Close takes precedence over the latch.
+// Waits, which start after BroadcastLatch returns, always observe the latch. To make waits, which are about to park concurrently,
+// observe it too, call BroadcastLatch with locker held (as with any state change followed by a broadcast).
+// Predicate loops busy-wait while the latch is set, unless their predicates check [commonCond.IsLatched].
+func (c *commonCond) BroadcastLatch() {
+	c.latched.Store(true)
+	c.broadcast()
+}
+
+// ClearLatch clears the latch set by [commonCond.BroadcastLatch] and reports if it was set. Wait methods block again.
+func (c *commonCond) ClearLatch() bool {
+	return c.latched.Swap(false)
+}
+
+// IsLatched reports if the latch set by [commonCond.BroadcastLatch] is set.
+func (c *commonCond) IsLatched() bool {
+	return c.latched.Load()
+}
+
+// isLatched reports if Wait methods must return without parking due to the latch. Close takes precedence.
+func (c *commonCond) isLatched() bool {
+	return c.latched.Load() && !c.closed.Load()
+}
@@ -0,0 +1,77 @@
+package cond_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestBroadcastLatch(t *testing.T) {
+	c := New(&sync.Mutex{})
+	parked, result := c.WaitReady()
+	<-parked
+
+	c.L.Lock()
+	c.BroadcastLatch()
+	c.L.Unlock()
+	if !<-result {
+		t.Fatal("want parked waiter to be woken by latch")
+	}
+
+	// Late arrivals return immediately.
+	c.L.Lock()
+	if !c.Wait() {
+		t.Fatal("want true for latched Cond")
+	}
+	if ok, err := c.WaitWithContext(context.Background()); !ok || err != nil {
+		t.Fatalf("want true and nil, got %v and %v", ok, err)
+	}
+	shutdown := c.WaitFor(c.IsLatched)
+	c.L.Unlock()
+	if !shutdown {
+		t.Fatal("want predicate observing latch to hold")
+	}
+	if c.WaitCount() != 0 {
+		t.Fatal("latched waits must not park")
+	}
+
+	// Predicate loops release locker while latched, so other goroutines can make progress.
+	ready := false
+	go func() {
+		c.L.Lock()
+		ready = true
+		c.L.Unlock()
+	}()
+	c.L.Lock()
+	if !c.WaitFor(func() bool { return ready }) {
+		t.Fatal("want predicate to hold")
+	}
+	c.L.Unlock()
+
+	if !c.ClearLatch() || c.IsLatched() {
+		t.Fatal("want latch cleared")
+	}
+	if c.ClearLatch() {
+		t.Fatal("want false for cleared latch")
+	}
+	parked, result = c.WaitReady()
+	<-parked
+	if c.WaitCount() != 1 {
+		t.Fatal("want waits to park after ClearLatch")
+	}
+	c.Signal(1)
+	<-result
+}
+
+func TestBroadcastLatchClosed(t *testing.T) {
+	c := NewRW(&sync.RWMutex{})
+	c.BroadcastLatch()
+	c.Close()
+	c.L.RLock()
+	defer c.L.RUnlock()
+	if c.Wait() {
+		t.Fatal("want false: Close takes precedence over latch")
+	}
+}
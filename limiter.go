@@ -0,0 +1,36 @@
+package cond
+
+import "sync/atomic"
+
+// WaitLimiter caps the number of goroutines simultaneously parked on all Conds/RWConds sharing it (see [WithWaitLimiter]).
+// All methods are thread safe.
+type WaitLimiter struct {
+	max    int64
+	parked atomic.Int64
+}
+
+// NewWaitLimiter returns WaitLimiter, which allows at most max parked goroutines. max <= 0 allows none.
+func NewWaitLimiter(max int) *WaitLimiter {
+	return &WaitLimiter{max: int64(max)}
+}
+
+// Parked returns current number of goroutines parked under the limiter.
+func (l *WaitLimiter) Parked() int {
+	return int(l.parked.Load())
+}
+
+func (l *WaitLimiter) acquire() bool {
+	for {
+		n := l.parked.Load()
+		if n >= l.max {
+			return false
+		}
+		if l.parked.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+func (l *WaitLimiter) release() {
+	l.parked.Add(-1)
+}
@@ -0,0 +1,73 @@
+package cond_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWaitLimiter(t *testing.T) {
+	lim := NewWaitLimiter(3)
+	a := New(&sync.Mutex{}, WithWaitLimiter(lim))
+	b := NewRW(&sync.RWMutex{}, WithWaitLimiter(lim))
+
+	var results []<-chan bool
+	for _, c := range []interface {
+		WaitReady() (<-chan struct{}, <-chan bool)
+	}{a, b, a} {
+		parked, result := c.WaitReady()
+		<-parked
+		results = append(results, result)
+	}
+	if lim.Parked() != 3 {
+		t.Fatalf("want 3 parked, got %d", lim.Parked())
+	}
+
+	// The global cap is reached for both Conds.
+	b.L.RLock()
+	if b.Wait() {
+		t.Fatal("want false over the limit")
+	}
+	b.L.RUnlock()
+	a.L.Lock()
+	if ok, err := a.WaitWithContext(context.Background()); ok || err != ErrWaitLimit {
+		t.Fatalf("want false and ErrWaitLimit, got %v and %v", ok, err)
+	}
+	if ok, err := a.WaitWithContextCause(context.Background()); ok || err != ErrWaitLimit {
+		t.Fatalf("WaitWithContextCause: want false and ErrWaitLimit, got %v and %v", ok, err)
+	}
+	if ok, err := a.WaitWithContexts(context.Background(), context.Background()); ok || err != ErrWaitLimit {
+		t.Fatalf("WaitWithContexts: want false and ErrWaitLimit, got %v and %v", ok, err)
+	}
+	if a.WaitForPoll(func() bool { return false }, time.Millisecond, time.Millisecond) {
+		t.Fatal("WaitForPoll: want false over the limit")
+	}
+	a.L.Unlock()
+	if a.WaitSeq(a.Seq()) {
+		t.Fatal("WaitSeq: want false over the limit")
+	}
+	if a.WaitCount()+b.WaitCount() != 3 {
+		t.Fatal("rejected waits must not park")
+	}
+
+	// A released slot can be used by another Cond.
+	a.Signal(1)
+	<-results[0]
+	parked, result := b.WaitReady()
+	<-parked
+	if lim.Parked() != 3 {
+		t.Fatalf("want 3 parked, got %d", lim.Parked())
+	}
+	a.Close()
+	b.Close()
+	<-result
+	for _, r := range results[1:] {
+		<-r
+	}
+	if lim.Parked() != 0 {
+		t.Fatalf("want 0 parked, got %d", lim.Parked())
+	}
+}
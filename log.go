@@ -0,0 +1,33 @@
+package cond
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LevelTrace is the level of per-wake records of [WithLogger], which is more verbose than debug.
+const LevelTrace = slog.LevelDebug - 4
+
+func (c *commonCond) log(level slog.Level, msg string, args ...any) {
+	ctx := context.Background()
+	if !c.opts.logger.Enabled(ctx, level) {
+		return
+	}
+	c.opts.logger.Log(ctx, level, msg, append([]any{slog.String("cond", c.opts.name)}, args...)...)
+}
+
+// logPark is called right after the goroutine is counted as waiting.
+func (c *commonCond) logPark() {
+	if c.s.WaitCount() == 1 {
+		c.log(slog.LevelDebug, "cond: first waiter")
+	}
+}
+
+// logWake is called right after the goroutine is uncounted.
+func (c *commonCond) logWake() {
+	n := c.s.WaitCount()
+	c.log(LevelTrace, "cond: wake", slog.Int("waiting", n))
+	if n == 0 {
+		c.log(slog.LevelDebug, "cond: no waiters")
+	}
+}
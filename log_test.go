@@ -0,0 +1,81 @@
+package cond_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+// recordHandler captures records at or above level.
+type recordHandler struct {
+	level   slog.Level
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(_ context.Context, l slog.Level) bool { return l >= h.level }
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler           { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler                { return h }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	h.records = append(h.records, r)
+	h.mu.Unlock()
+	return nil
+}
+
+// events returns "level message" of captured records and checks their "cond" attribute.
+func (h *recordHandler) events(t *testing.T, name string) []string {
+	t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var events []string
+	for _, r := range h.records {
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "cond" && a.Value.String() != name {
+				t.Errorf("want cond=%s, got %s", name, a.Value)
+			}
+			return true
+		})
+		events = append(events, r.Level.String()+" "+r.Message)
+	}
+	return events
+}
+
+func TestWithLogger(t *testing.T) {
+	for _, tc := range []struct {
+		level slog.Level
+		want  []string
+	}{
+		{slog.LevelDebug, []string{"DEBUG cond: first waiter", "DEBUG cond: no waiters", "DEBUG cond: closed"}},
+		{LevelTrace, []string{"DEBUG cond: first waiter", "DEBUG-4 cond: wake", "DEBUG cond: no waiters", "DEBUG cond: closed"}},
+		{slog.LevelInfo, nil},
+	} {
+		h := &recordHandler{level: tc.level}
+		c := New(&sync.Mutex{}, WithLogger(slog.New(h)), WithName("orders"))
+		done := make(chan struct{})
+		go func() {
+			c.L.Lock()
+			c.Wait()
+			c.L.Unlock()
+			close(done)
+		}()
+		waitParked(c, 1)
+		c.Signal(1)
+		<-done
+		c.Close()
+
+		got := h.events(t, "orders")
+		if len(got) != len(tc.want) {
+			t.Fatalf("level %v: want %v, got %v", tc.level, tc.want, got)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("level %v: want %v, got %v", tc.level, tc.want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package cond
+
+import (
+	"log/slog"
+	"runtime/pprof"
+	"time"
+)
+
+// Option configures Cond/RWCond created by [New] or [NewRW].
+type Option func(*options)
+
+type options struct {
+	broadcastObserver func(woken int)
+	heartbeat         time.Duration
+	deadlockTimeout   time.Duration
+	deadlockHandler   func(waiting int)
+	preCloseHook      func()
+	onClose           func()
+	ewmaAlpha         float64
+	lockContention    func(acquireDelay time.Duration)
+	scale             *scalePolicy
+	pprofLabels       *pprof.LabelSet
+	stackCapture      bool
+	strict            bool
+	name              string
+	limiter           *WaitLimiter
+	signalRate        bool
+	defaultTimeout    time.Duration
+	logger            *slog.Logger
+	expvar            string
+	errorOnClose      bool
+	waitTokens        bool
+	wakeOrder         bool
+	parent            *Cond
+	panicWrapper      bool
+	waiterQueue       int
+	spurious          float64
+	signalBuffer      int
+}
+
+// WithBroadcastObserver sets f, which is called after each broadcast with the number of goroutines woken by it.
+// The number is sampled right before the broadcast, so goroutines, which were concurrently woken by Signal, may be counted too.
+// Close does not invoke f.
+func WithBroadcastObserver(f func(woken int)) Option {
+	return func(o *options) {
+		o.broadcastObserver = f
+	}
+}
+
+// WithHeartbeat starts a goroutine, which broadcasts every interval until Cond/RWCond is closed.
+// Waiting goroutines wake periodically even without explicit signals, which is handy for predicate loops depending on external state.
+// Heartbeats are not counted by [commonCond.MissedSignals] and [commonCond.SignalRate], so an idle Cond/RWCond does not look overloaded.
+// The goroutine exits on Close, so Cond/RWCond created with this option must be closed to not leak it. Ignored if interval <= 0.
+func WithHeartbeat(interval time.Duration) Option {
+	return func(o *options) {
+		o.heartbeat = interval
+	}
+}
+
+// WithDeadlockDetection starts a goroutine, which calls handler if WaitCount() > 0 stays unchanged and there were no signals or broadcasts for timeout.
+// If handler is nil, it logs via the standard log package. Each stuck state is reported once.
+// It is a heuristic for tests and CI, not a guarantee: a legitimately long wait is reported as well (false positive),
+// so timeout should be well above the longest expected wait. A stuck state may take up to 2*timeout to be reported.
+// The goroutine exits on Close, so Cond/RWCond created with this option must be closed to not leak it. Ignored if timeout <= 0.
+func WithDeadlockDetection(timeout time.Duration, handler func(waiting int)) Option {
+	return func(o *options) {
+		o.deadlockTimeout = timeout
+		o.deadlockHandler = handler
+	}
+}
+
+// WithPreCloseHook sets f, which is called by the first Close after Cond/RWCond is marked closed, but before waiting goroutines are awoken.
+// Goroutines, which re-check shared state after waking due to close, observe effects of f. f runs before [WithOnClose] hook.
+// If f panics, Close still wakes waiting goroutines and runs the remaining hooks before the panic propagates.
+func WithPreCloseHook(f func()) Option {
+	return func(o *options) {
+		o.preCloseHook = f
+	}
+}
+
+// WithOnClose sets f, which is called by the first Close after waiting goroutines are awoken.
+func WithOnClose(f func()) Option {
+	return func(o *options) {
+		o.onClose = f
+	}
+}
+
+// WithWaitCountEWMA enables [commonCond.WaitCountEWMA] with smoothing factor alpha in (0, 1]: on every transition
+// the average becomes alpha*WaitCount() + (1-alpha)*average. Higher alpha reacts faster. Ignored if alpha is out of range.
+func WithWaitCountEWMA(alpha float64) Option {
+	return func(o *options) {
+		if alpha > 0 && alpha <= 1 {
+			o.ewmaAlpha = alpha
+		}
+	}
+}
+
+// WithLockContentionObserver sets f, which is called with how long the final re-Lock of locker took in every Wait method,
+// which parked. A slow re-Lock means that the locker, not Cond/RWCond, is the bottleneck. f is called with locker locked.
+func WithLockContentionObserver(f func(acquireDelay time.Duration)) Option {
+	return func(o *options) {
+		o.lockContention = f
+	}
+}
+
+// WithScalePolicy starts a goroutine, which calls scale with WaitCount() whenever it changes, so Cond/RWCond pressure can drive
+// autoscaling of workers directly. The result is clamped to [min, max] (max < min is treated as max == min) and reported by
+// [commonCond.ScaleTarget]. Calls are debounced: a burst of changes results in a single call with the count after the burst, and
+// scale is not called, if the count did not change. These calls run in that goroutine outside of locker, so scale may start or
+// stop workers itself. scale is also called once with 0 by the constructor in the caller's goroutine, so ScaleTarget is set once
+// the constructor returns. The goroutine exits on Close, so Cond/RWCond created with this option must be closed to not leak it.
+// Ignored if scale is nil.
+func WithScalePolicy(min, max int, scale func(waiting int) (desired int)) Option {
+	return func(o *options) {
+		if scale == nil {
+			return
+		}
+		if max < min {
+			max = min
+		}
+		o.scale = &scalePolicy{min: min, max: max, scale: scale}
+	}
+}
+
+// WithPprofLabels sets pprof labels, which goroutines carry while parked in Wait methods with a context (WaitWithContext,
+// WaitForContext, ...), so goroutine profiles group them by the Cond/RWCond they are blocked on. The labels are added to
+// labels of ctx, which are restored after Wait returns, as with [pprof.Do]. Wait methods without a context do not set labels,
+// as they can not restore the labels of the caller. Ignored if labels is empty.
+func WithPprofLabels(labels map[string]string) Option {
+	return func(o *options) {
+		if len(labels) == 0 {
+			return
+		}
+		kv := make([]string, 0, 2*len(labels))
+		for k, v := range labels {
+			kv = append(kv, k, v)
+		}
+		ls := pprof.Labels(kv...)
+		o.pprofLabels = &ls
+	}
+}
+
+// WithStackCapture makes every parked goroutine record when it parked and a short stack of its Wait call,
+// which are reported by [commonCond.DumpWaiters]. Capturing costs a runtime.Callers call and a mutex-protected registry update
+// on every park and wake, so enable it for debugging hangs rather than on hot paths.
+func WithStackCapture() Option {
+	return func(o *options) {
+		o.stackCapture = true
+	}
+}
+
+// WithStrictUseAfterClose makes Wait methods panic, if they are called after Close, instead of returning false.
+// Waits, which were parked before Close, still return false. The panic message includes the name set by [WithName].
+// It surfaces logic errors, where code keeps waiting on a dead Cond/RWCond.
+func WithStrictUseAfterClose() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithName sets the name of Cond/RWCond used in diagnostics, e.g. by [WithStrictUseAfterClose].
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithWaitLimiter makes Wait methods consult l before parking, so many Conds/RWConds sharing l have a global cap on parked goroutines.
+// If l has no free slots, Wait methods return without parking and unlocking locker: methods with context return false and [ErrWaitLimit],
+// others return false as if Cond/RWCond was closed (use IsClosed to tell them apart). Ignored if l is nil.
+func WithWaitLimiter(l *WaitLimiter) Option {
+	return func(o *options) {
+		o.limiter = l
+	}
+}
+
+// WithSignalRate enables [commonCond.SignalRate] for alerting on signal storms.
+func WithSignalRate() Option {
+	return func(o *options) {
+		o.signalRate = true
+	}
+}
+
+// WithDefaultTimeout sets the initial default timeout of plain Wait (see [commonCond.SetDefaultWaitTimeout]).
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.defaultTimeout = d
+	}
+}
+
+// WithLogger makes Cond/RWCond log its lifecycle to l at debug level: close, first waiter and becoming empty.
+// Every wake is logged at [LevelTrace], so it is only emitted, if the handler of l enables that level.
+// Records carry the name set by [WithName] as "cond" attribute. Waiter events are based on WaitCount() sampled at transitions,
+// so concurrent transitions may log an event twice or skip it.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithExpvar publishes [commonCond.Stats] as an expvar.Map under name, so they appear on /debug/vars.
+// Each field of Stats is a key of the map, which is read on every export. expvar can not unpublish, so the name stays published
+// and keeps the latest Cond/RWCond created with it reachable: a Cond/RWCond created with a name, which is already published by this option,
+// replaces the previous one. If name is taken by another expvar variable, the option is ignored. Ignored if name is empty.
+func WithExpvar(name string) Option {
+	return func(o *options) {
+		o.expvar = name
+	}
+}
+
+// WithErrorOnClose makes Wait methods, which return an error (WaitWithContext, WaitForTimeout, ...), return false and [ErrClosed],
+// if Cond/RWCond was closed, instead of false and nil. ErrClosed never wraps a context error, so errors.Is tells close from
+// cancellation. If ctx is cancelled concurrently with Close, either error may be returned.
+func WithErrorOnClose() Option {
+	return func(o *options) {
+		o.errorOnClose = true
+	}
+}
+
+// WithWaitTokens makes EndWait panic, if its [WaitToken] was already ended or was returned by another Cond/RWCond.
+// Tokens are tracked until they are ended, so BeginWait without EndWait leaks a map entry. Meant for debug builds and tests.
+func WithWaitTokens() Option {
+	return func(o *options) {
+		o.waitTokens = true
+	}
+}
+
+// WithWakeOrderTracking records the order, in which parked goroutines are awoken, for [commonCond.WakeOrder], so tests can assert
+// fairness of wakes. A goroutine is recorded when it is awoken and before it re-acquires locker, so goroutines awoken by one call
+// (e.g. Signal(2) or Broadcast) may be recorded in any order. Records are never dropped and every park and wake takes a mutex,
+// so it is meant for tests only.
+func WithWakeOrderTracking() Option {
+	return func(o *options) {
+		o.wakeOrder = true
+	}
+}
+
+// WithParent closes Cond/RWCond, when parent is closed, so Conds/RWConds form a tree with cascading shutdown.
+// The child is registered on parent as a closer (see [commonCond.AddCloser]), so no goroutine is spawned: the first Close of parent closes
+// its children after waking its own waiters, and they close their children in turn. Closing a child does not close parent and unregisters
+// the child from parent, so short-lived children are not retained by a long-lived parent. If parent is already closed, the child
+// is created closed. Ignored if parent is nil.
+func WithParent(parent *Cond) Option {
+	return func(o *options) {
+		o.parent = parent
+	}
+}
+
+// WithPanicWrapper makes predicate based Wait methods (WaitFor, WaitForTimeout, WaitGuarded, ...) recover panics of predicates
+// and re-panic with [PanicError], which carries the name set by [WithName] and the method, so panics show which Cond/RWCond they came from.
+// Locker state is same as with the original panic. Without this option predicates are called directly.
+func WithPanicWrapper() Option {
+	return func(o *options) {
+		o.panicWrapper = true
+	}
+}
+
+// WithWaiterQueue bounds the number of goroutines parked on Cond/RWCond by max. Unlike [WithWaitLimiter] excess callers are not
+// rejected: they release locker and block in a queue (see [commonCond.QueuedWaiters]) until a parked goroutine is awoken and
+// frees its slot. Signals are not delivered to queued goroutines, so a queued Wait does not park, when it gets a slot, but
+// returns true and re-acquires locker, and the caller re-checks its predicate like after any wake. Close releases queued
+// goroutines with false, and methods with context also return, when ctx is cancelled. Ignored if max <= 0.
+func WithWaiterQueue(max int) Option {
+	return func(o *options) {
+		o.waiterQueue = max
+	}
+}
+
+// WithSpuriousWakeups is a testing aid, which makes every wait end with probability p without a signal: Wait methods release
+// locker, yield and re-acquire it, and return true as if they were awoken. It verifies that callers re-check their predicate
+// after every wake. Waits on closed Cond/RWCond still return false. Ignored if p is not in (0, 1]. Do not use it in production.
+func WithSpuriousWakeups(p float64) Option {
+	return func(o *options) {
+		if p > 0 && p <= 1 {
+			o.spurious = p
+		}
+	}
+}
+
+// WithSignalBuffer retains up to size signals of Signal, which found no waiting goroutine, and hands them to the next waits: a
+// Wait method, which finds a buffered signal, consumes it and returns true without unlocking locker. Signals are interchangeable
+// tokens, so when the buffer is full, the excess signals are dropped (dropping the oldest or the newest ones is the same).
+// Broadcasts are never buffered (see [commonCond.BroadcastLatch] for sticky broadcasts) and Close drops buffered signals. A
+// buffered signal, which is handed to a goroutine, which is parking, is counted by Signal as awoken. Waits park with a
+// cancellable context, so they allocate, and [Cond.WaitEpoch] reports epoch 0. Ignored if size <= 0.
+func WithSignalBuffer(size int) Option {
+	return func(o *options) {
+		o.signalBuffer = size
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,37 @@
+package cond
+
+import (
+	"slices"
+	"sync"
+)
+
+// WaiterID identifies a wait tracked by [WithWakeOrderTracking]. IDs are assigned in the order goroutines start waiting, starting from 1.
+type WaiterID uint64
+
+// wakeOrder records wakes for [WithWakeOrderTracking].
+type wakeOrder struct {
+	mu    sync.Mutex
+	next  WaiterID
+	woken []WaiterID
+}
+
+func (o *wakeOrder) park() WaiterID {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.next++
+	return o.next
+}
+
+func (o *wakeOrder) wake(id WaiterID) {
+	o.mu.Lock()
+	o.woken = append(o.woken, id)
+	o.mu.Unlock()
+}
+
+// WakeOrder returns IDs of waits, which parked and were awoken, in the order they were awoken (see [WithWakeOrderTracking]).
+// IDs increase in the order goroutines started waiting, so FIFO wakes produce a sorted slice. Returns nil without the option.
+func (c *commonCond) WakeOrder() []WaiterID {
+	c.order.mu.Lock()
+	defer c.order.mu.Unlock()
+	return slices.Clone(c.order.woken)
+}
@@ -0,0 +1,43 @@
+package cond
+
+import "fmt"
+
+// PanicError is the value, with which predicate based Wait methods re-panic, if a predicate panicked and [WithPanicWrapper] is set.
+type PanicError struct {
+	// Name is the name of Cond/RWCond set by [WithName].
+	Name string
+	// Op is the Wait method, e.g. "WaitFor".
+	Op string
+	// Value is the value, with which the predicate panicked.
+	Value any
+}
+
+func (e *PanicError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("cond: panic in %s predicate of Cond %q: %v", e.Op, e.Name, e.Value)
+	}
+	return fmt.Sprintf("cond: panic in %s predicate: %v", e.Op, e.Value)
+}
+
+// Unwrap returns Value, if it is an error, so errors.Is and errors.As see the original panic.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// predicate returns pred, which re-panics with [PanicError] for op, if [WithPanicWrapper] is set, or pred itself otherwise.
+func (c *commonCond) predicate(op string, pred func() bool) func() bool {
+	if !c.opts.panicWrapper {
+		return pred
+	}
+	return func() bool {
+		defer c.repanic(op)
+		return pred()
+	}
+}
+
+func (c *commonCond) repanic(op string) {
+	if r := recover(); r != nil {
+		panic(&PanicError{Name: c.opts.name, Op: op, Value: r})
+	}
+}
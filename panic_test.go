@@ -0,0 +1,56 @@
+package cond_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+// recoverWait calls wait with locker of c held and returns the recovered panic value.
+func recoverWait(c *Cond, wait func()) (r any) {
+	c.L.Lock()
+	defer func() {
+		r = recover()
+		c.L.Unlock()
+	}()
+	wait()
+	return nil
+}
+
+func TestWithPanicWrapper(t *testing.T) {
+	c := New(&sync.Mutex{}, WithName("jobs"), WithPanicWrapper())
+	errBoom := errors.New("boom")
+	r := recoverWait(c, func() {
+		c.WaitFor(func() bool { panic(errBoom) })
+	})
+	pe, ok := r.(*PanicError)
+	if !ok {
+		t.Fatalf("want *PanicError, got %T", r)
+	}
+	if msg := pe.Error(); !strings.Contains(msg, `"jobs"`) || !strings.Contains(msg, "WaitFor") {
+		t.Fatalf("want Cond name and method in %q", msg)
+	}
+	if !errors.Is(pe, errBoom) {
+		t.Fatal("want original error to be wrapped")
+	}
+
+	r = recoverWait(c, func() {
+		c.WaitForErr(func() (bool, error) { panic("bad state") })
+	})
+	if pe, ok := r.(*PanicError); !ok || pe.Op != "WaitForErr" || pe.Value != "bad state" || pe.Unwrap() != nil {
+		t.Fatalf("want PanicError of WaitForErr with the original value, got %#v", r)
+	}
+}
+
+func TestWithoutPanicWrapper(t *testing.T) {
+	c := New(&sync.Mutex{})
+	r := recoverWait(c, func() {
+		c.WaitFor(func() bool { panic("bad state") })
+	})
+	if r != "bad state" {
+		t.Fatalf("want the original panic, got %#v", r)
+	}
+}
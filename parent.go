@@ -0,0 +1,23 @@
+package cond
+
+// childCloser closes a child Cond/RWCond registered by [WithParent] on its parent.
+type childCloser struct {
+	c *commonCond
+}
+
+func (cl childCloser) Close() error {
+	cl.c.Close()
+	return nil
+}
+
+// attach registers c to be closed by its parent. If the parent is already closed, c is closed at once.
+func (c *commonCond) attach(parent *Cond) {
+	if !parent.AddCloser(childCloser{c}) {
+		c.Close()
+	}
+}
+
+// detach unregisters closed c from its parent, so the parent does not retain it.
+func (c *commonCond) detach(parent *Cond) {
+	parent.removeCloser(childCloser{c})
+}
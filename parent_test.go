@@ -0,0 +1,36 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWithParent(t *testing.T) {
+	root := New(&sync.Mutex{})
+	mid := New(&sync.Mutex{}, WithParent(root))
+	leaf := NewRW(&sync.RWMutex{}, WithParent(mid))
+	sibling := New(&sync.Mutex{}, WithParent(root))
+
+	parked, result := leaf.WaitReady()
+	<-parked
+
+	// Closing a child does not close its parent.
+	sibling.Close()
+	if root.IsClosed() {
+		t.Fatal("closing a child closed the parent")
+	}
+
+	root.Close()
+	if !mid.IsClosed() || !leaf.IsClosed() {
+		t.Fatal("want children closed transitively")
+	}
+	if <-result {
+		t.Fatal("want waiter of a grandchild to be woken by close")
+	}
+
+	if late := New(&sync.Mutex{}, WithParent(root)); !late.IsClosed() {
+		t.Fatal("want child of closed parent to be created closed")
+	}
+}
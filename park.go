@@ -0,0 +1,223 @@
+package cond
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/nursik/wake"
+)
+
+// park is the single place, where goroutines wait for signal. It Unlocks l, blocks until awaken (returns true)
+// or Cond/RWCond was closed (returns false), and Locks l again. Optional instrumentation applies to all Wait methods through it.
+// wake uncounts the goroutine before Locking l, so WaitCount stays consistent even if l.Lock panics.
+// Gauges of this package (e.g. UpgradeWaiters) are decremented with defer for the same reason.
+func (c *commonCond) park(l sync.Locker) bool {
+	ok, _ := c.parkEpoch(l)
+	return ok
+}
+
+// parkEpoch is same as park, but also reports the epoch of [commonCond.SignalEpoch], which woke the goroutine, or 0.
+func (c *commonCond) parkEpoch(l sync.Locker) (bool, uint64) {
+	orig := l
+	if c.opts.signalBuffer > 0 {
+		// Buffered signals cancel waits, which are already parking.
+		ok, _ := c.parkContext(l, context.Background(), false)
+		return ok, 0
+	}
+	c.checkStrict()
+	if c.isLatched() {
+		wakeSpuriously(l)
+		return true, 0
+	}
+	if c.spurious() {
+		wakeSpuriously(l)
+		return true, 0
+	}
+	if q := c.queue; q != nil {
+		if !q.tryAcquire() {
+			ok, _ := q.wait(l, context.Background(), c.done)
+			return ok, 0
+		}
+		defer q.release()
+	}
+	if lim := c.opts.limiter; lim != nil {
+		if !lim.acquire() {
+			return false, 0
+		}
+		defer lim.release()
+	}
+	if c.instrumented {
+		l = &parkLocker{c: c, l: l}
+	}
+	armLastOut(orig)
+	closed := c.closed.Load()
+	ok := wake.UnsafeWait(c.r, l)
+	var epoch uint64
+	if ok {
+		c.consume()
+		epoch = c.wokenEpoch()
+	} else if !closed {
+		c.closeWakes.Add(1)
+	}
+	return ok, epoch
+}
+
+// parkContext is same as park, but also unblocks on ctx cancellation. callerCtx reports that ctx was supplied by the caller
+// of a Wait method, so the goroutine leaves for good on cancellation and is credited to SignalWithContext calls in flight.
+// Contexts created internally (poll intervals, default timeouts, sequence checks) must not be credited, as their waits park again.
+func (c *commonCond) parkContext(l sync.Locker, ctx context.Context, callerCtx bool) (bool, error) {
+	orig := l
+	c.checkStrict()
+	if c.isLatched() {
+		wakeSpuriously(l)
+		return true, nil
+	}
+	if c.spurious() {
+		wakeSpuriously(l)
+		return true, nil
+	}
+	var bl *bufferLocker
+	if c.opts.signalBuffer > 0 {
+		if c.takeBuffered() {
+			return true, nil
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		bl = &bufferLocker{l: l, c: c, cancel: cancel}
+		l = bl
+	}
+	if q := c.queue; q != nil {
+		if !q.tryAcquire() {
+			return q.wait(l, ctx, c.done)
+		}
+		defer q.release()
+	}
+	if lim := c.opts.limiter; lim != nil {
+		if !lim.acquire() {
+			return false, ErrWaitLimit
+		}
+		defer lim.release()
+	}
+	if c.instrumented {
+		l = &parkLocker{c: c, l: l}
+	}
+	if c.opts.pprofLabels != nil && callerCtx {
+		defer c.label(ctx)()
+	}
+	armLastOut(orig)
+	closed := c.closed.Load()
+	ok, err := wake.UnsafeWaitContext(c.r, l, ctx)
+	if bl != nil && bl.took {
+		if ok {
+			// Awoken by a signal after taking a buffered one, return the buffered signal.
+			c.consume()
+			c.buffer(1)
+		}
+		return true, nil
+	}
+	if ok {
+		c.consume()
+	} else if err != nil {
+		if callerCtx {
+			c.creditCancel()
+		}
+	} else if !closed {
+		c.closeWakes.Add(1)
+	}
+	return ok, err
+}
+
+// checkStrict panics on use after close, if [WithStrictUseAfterClose] is set.
+func (c *commonCond) checkStrict() {
+	if !c.opts.strict || !c.closed.Load() {
+		return
+	}
+	if c.opts.name != "" {
+		panic(fmt.Sprintf("cond: Wait on closed Cond %q", c.opts.name))
+	}
+	panic("cond: Wait on closed Cond")
+}
+
+// label sets [WithPprofLabels] labels on top of labels of ctx and returns func, which restores labels of ctx.
+func (c *commonCond) label(ctx context.Context) func() {
+	pprof.SetGoroutineLabels(pprof.WithLabels(ctx, *c.opts.pprofLabels))
+	return func() {
+		pprof.SetGoroutineLabels(ctx)
+	}
+}
+
+// parkLocker runs instrumentation right after the goroutine is counted as waiting (Unlock) and right after it is uncounted (Lock).
+// Neither is called if the goroutine does not park.
+type parkLocker struct {
+	c  *commonCond
+	l  sync.Locker
+	w  *waiter
+	id WaiterID
+}
+
+func (l *parkLocker) Lock() {
+	if l.id != 0 {
+		l.c.order.wake(l.id)
+	}
+	l.c.onTransition()
+	if l.c.opts.logger != nil {
+		l.c.logWake()
+	}
+	if l.w != nil {
+		l.c.untrack(l.w)
+	}
+	if f := l.c.opts.lockContention; f != nil {
+		start := time.Now()
+		l.l.Lock()
+		f(time.Since(start))
+		return
+	}
+	l.l.Lock()
+}
+
+func (l *parkLocker) Unlock() {
+	if l.c.opts.wakeOrder {
+		// Assigned before releasing locker, so waits serialized by locker get IDs in the order they were counted.
+		l.id = l.c.order.park()
+	}
+	l.l.Unlock()
+	l.c.onTransition()
+	if l.c.opts.logger != nil {
+		l.c.logPark()
+	}
+	if l.c.opts.stackCapture {
+		l.w = l.c.track()
+	}
+}
+
+func (c *commonCond) onTransition() {
+	if c.opts.ewmaAlpha > 0 {
+		c.sampleEWMA()
+	}
+	if c.opts.scale != nil {
+		c.kickScale()
+	}
+}
+
+func (c *commonCond) sampleEWMA() {
+	n := float64(c.s.WaitCount())
+	a := c.opts.ewmaAlpha
+	for {
+		old := c.ewma.Load()
+		v := a*n + (1-a)*math.Float64frombits(old)
+		if c.ewma.CompareAndSwap(old, math.Float64bits(v)) {
+			return
+		}
+	}
+}
+
+// WaitCountEWMA returns exponentially weighted moving average of WaitCount() enabled by [WithWaitCountEWMA] (0 without the option).
+// The average is updated at every transition (a goroutine starts or stops waiting), not on a wall clock, so it does not decay while nothing changes.
+func (c *commonCond) WaitCountEWMA() float64 {
+	return math.Float64frombits(c.ewma.Load())
+}
@@ -0,0 +1,160 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWaitCountEWMA(t *testing.T) {
+	c := New(&sync.Mutex{}, WithWaitCountEWMA(0.5))
+	expect := func(want float64) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for c.WaitCountEWMA() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("want EWMA %v, got %v", want, c.WaitCountEWMA())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wait := func() <-chan bool {
+		ch := make(chan bool, 1)
+		go func() {
+			c.L.Lock()
+			ch <- c.Wait()
+			c.L.Unlock()
+		}()
+		return ch
+	}
+
+	if c.WaitCountEWMA() != 0 {
+		t.Fatal("want 0 initially")
+	}
+	first := wait()
+	expect(0.5) // 0.5*1 + 0.5*0
+	second := wait()
+	expect(1.25) // 0.5*2 + 0.5*0.5
+	c.Signal(1)
+	select {
+	case <-first:
+	case <-second:
+	}
+	expect(1.125) // 0.5*1 + 0.5*1.25
+	c.Close()
+}
+
+// slowLocker delays Lock by delay.
+type slowLocker struct {
+	sync.Mutex
+	delay time.Duration
+}
+
+func (l *slowLocker) Lock() {
+	time.Sleep(l.delay)
+	l.Mutex.Lock()
+}
+
+func TestLockContentionObserver(t *testing.T) {
+	delays := make(chan time.Duration, 1)
+	l := &slowLocker{}
+	c := New(l, WithLockContentionObserver(func(d time.Duration) {
+		delays <- d
+	}))
+	done := make(chan bool)
+	go func() {
+		l.Mutex.Lock()
+		done <- c.Wait()
+		l.Unlock()
+	}()
+	waitParked(c, 1)
+	l.delay = 20 * time.Millisecond
+	c.Signal(1)
+	if !<-done {
+		t.Fatal("want true")
+	}
+	if d := <-delays; d < l.delay {
+		t.Fatalf("want observed delay >= %v, got %v", l.delay, d)
+	}
+}
+
+//go:noinline
+func lockUnlock(l sync.Locker) {
+	l.Lock()
+	l.Unlock()
+}
+
+// BenchmarkLockerDispatch measures the cost of calling Lock/Unlock of *sync.Mutex through sync.Locker, which every Wait does,
+// against direct calls. lockUnlock is not inlined, so the interface calls are not devirtualized.
+func BenchmarkLockerDispatch(b *testing.B) {
+	var m sync.Mutex
+	b.Run("concrete", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m.Lock()
+			m.Unlock()
+		}
+	})
+	b.Run("interface", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			lockUnlock(&m)
+		}
+	})
+}
+
+// indirectLocker adds one more level of interface dispatch to Lock and Unlock.
+type indirectLocker struct {
+	l sync.Locker
+}
+
+func (l *indirectLocker) Lock()   { l.l.Lock() }
+func (l *indirectLocker) Unlock() { l.l.Unlock() }
+
+// BenchmarkWaitLockerDispatch measures Wait/Signal round trips between two goroutines with *sync.Mutex as locker
+// and with the same mutex behind an extra interface call. The difference is an upper bound of what a concrete mutex
+// in the Wait path could save.
+func BenchmarkWaitLockerDispatch(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		l    func(m *sync.Mutex) sync.Locker
+	}{
+		{"mutex", func(m *sync.Mutex) sync.Locker { return m }},
+		{"indirect", func(m *sync.Mutex) sync.Locker { return &indirectLocker{m} }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var m sync.Mutex
+			l := bc.l(&m)
+			ping, pong := New(l), New(l)
+			turn := 0
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				l.Lock()
+				defer l.Unlock()
+				for {
+					for turn != 1 {
+						if !ping.Wait() {
+							return
+						}
+					}
+					turn = 0
+					pong.Signal(1)
+				}
+			}()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Lock()
+				turn = 1
+				ping.Signal(1)
+				for turn != 0 {
+					pong.Wait()
+				}
+				l.Unlock()
+			}
+			b.StopTimer()
+			ping.Close()
+			<-done
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package cond
+
+import "math"
+
+// Pause holds all subsequent signals until [commonCond.Resume]. Waiting goroutines stay parked while Cond/RWCond is paused.
+// Held signals are buffered as follows: Signal(n) calls accumulate n, SignalWithContext(ctx, n) is buffered as Signal(n) and returns immediately,
+// and any number of broadcasts collapse into a single broadcast, which supersedes accumulated signals.
+// Close is never held and drops buffered signals.
+func (c *commonCond) Pause() {
+	c.pauseMu.Lock()
+	c.paused.Store(true)
+	c.pauseMu.Unlock()
+}
+
+// Resume delivers signals held since [commonCond.Pause] and reports how many goroutines were awoken
+// (for a held broadcast, how many goroutines were waiting right before it, as with Signal(0)).
+// Calling Resume on Cond/RWCond, which is not paused, does nothing.
+func (c *commonCond) Resume() int {
+	c.pauseMu.Lock()
+	if !c.paused.Load() {
+		c.pauseMu.Unlock()
+		return 0
+	}
+	c.paused.Store(false)
+	n, all := c.pending, c.pendingAll
+	c.pending, c.pendingAll = 0, false
+	c.pauseMu.Unlock()
+
+	if all {
+		return c.broadcast()
+	}
+	if n > 0 {
+		return c.Signal(n)
+	}
+	return 0
+}
+
+// IsPaused reports if Cond/RWCond is paused.
+func (c *commonCond) IsPaused() bool {
+	return c.paused.Load()
+}
+
+// hold buffers Signal(n) (n <= 0 for broadcast) and reports true, if Cond/RWCond is paused.
+func (c *commonCond) hold(n int) bool {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	if !c.paused.Load() {
+		return false
+	}
+	if n <= 0 {
+		c.pendingAll = true
+	} else if c.pending > math.MaxInt-n {
+		c.pending = math.MaxInt
+	} else {
+		c.pending += n
+	}
+	return true
+}
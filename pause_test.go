@@ -0,0 +1,119 @@
+package cond_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestPauseResume(t *testing.T) {
+	c := New(&sync.Mutex{})
+	n := 5
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.L.Lock()
+			c.Wait()
+			c.L.Unlock()
+		}()
+	}
+	waitParked(c, n)
+
+	c.Pause()
+	if !c.IsPaused() {
+		t.Fatal("want paused")
+	}
+	if m := c.Signal(1); m != 0 {
+		t.Fatalf("want 0 awoken while paused, got %d", m)
+	}
+	c.Signal(2)
+	time.Sleep(10 * time.Millisecond)
+	if c.WaitCount() != n {
+		t.Fatalf("want %d waiters while paused, got %d", n, c.WaitCount())
+	}
+
+	// Accumulated Signal(1) and Signal(2).
+	if m := c.Resume(); m != 3 {
+		t.Fatalf("want 3 awoken on resume, got %d", m)
+	}
+	for c.WaitCount() != n-3 {
+		runtime.Gosched()
+	}
+
+	c.Pause()
+	c.Broadcast()
+	c.Broadcast()
+	c.Signal(1)
+	time.Sleep(10 * time.Millisecond)
+	if c.WaitCount() != n-3 {
+		t.Fatalf("want %d waiters while paused, got %d", n-3, c.WaitCount())
+	}
+	// The held broadcast reports the remaining waiters.
+	if m := c.Resume(); m != n-3 {
+		t.Fatalf("want %d awoken by held broadcast, got %d", n-3, m)
+	}
+	wg.Wait()
+
+	if c.Resume() != 0 {
+		t.Fatal("want 0 for Cond, which is not paused")
+	}
+}
+
+func TestPauseSignalRace(t *testing.T) {
+	c := New(&sync.Mutex{})
+	n := 200
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.L.Lock()
+			c.Wait()
+			c.L.Unlock()
+		}()
+	}
+	waitParked(c, n)
+
+	var signallers sync.WaitGroup
+	for i := 0; i < n; i++ {
+		signallers.Add(1)
+		go func() {
+			defer signallers.Done()
+			c.Signal(1)
+		}()
+	}
+	stop := make(chan struct{})
+	toggled := make(chan struct{})
+	go func() {
+		defer close(toggled)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			c.Pause()
+			c.Resume()
+		}
+	}()
+	signallers.Wait()
+	close(stop)
+	<-toggled
+	c.Resume()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("signals were lost, %d goroutines still wait", c.WaitCount())
+	}
+}
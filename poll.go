@@ -0,0 +1,96 @@
+package cond
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// poll is same as waitFor, but every park also ends after interval(attempt), so pred is re-checked even without signals.
+// Timeouts are private to the goroutine and never wake other waiters.
+func (c *commonCond) poll(l sync.Locker, pred func() bool, interval func(attempt int) time.Duration) bool {
+	for attempt := 0; !pred(); attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), interval(attempt))
+		ok, err := c.parkContext(l, ctx, false)
+		cancel()
+		// A wait rejected by WaitLimiter does not release locker, so parking again would spin.
+		if !ok && (err == nil || err == ErrWaitLimit) {
+			return false
+		}
+	}
+	return true
+}
+
+// doubling returns interval, which starts at initial and doubles up to max. max < initial is treated as max == initial.
+func doubling(initial, max time.Duration) func(int) time.Duration {
+	if max < initial {
+		max = initial
+	}
+	d := initial
+	return func(int) time.Duration {
+		cur := d
+		if d < max/2 {
+			d *= 2
+		} else {
+			d = max
+		}
+		return cur
+	}
+}
+
+// WaitForPoll is same as [Cond.WaitFor], but also re-checks pred after initial, 2*initial, ... up to max between checks,
+// so it suits predicates on external state, which may change without a signal. Signals and broadcasts still re-check pred immediately.
+// The timer is stopped when pred holds or Cond is closed. initial must be positive, otherwise pred is re-checked in a busy loop.
+func (c *Cond) WaitForPoll(pred func() bool, initial, max time.Duration) bool {
+	return c.poll(c.L, c.predicate("WaitForPoll", pred), doubling(initial, max))
+}
+
+// WaitForPoll is same as [RWCond.WaitFor], but also re-checks pred after initial, 2*initial, ... up to max between checks
+// (see [Cond.WaitForPoll]).
+func (c *RWCond) WaitForPoll(pred func() bool, initial, max time.Duration) bool {
+	return c.poll(c.rwl, c.predicate("WaitForPoll", pred), doubling(initial, max))
+}
+
+// waitForBackoff is same as waitFor, but after each unsuccessful wake it releases l for backoff(attempt) and re-checks pred
+// before parking again. Only Close interrupts the backoff.
+func (c *commonCond) waitForBackoff(l sync.Locker, pred func() bool, backoff func(attempt int) time.Duration) bool {
+	for attempt := 1; !pred(); attempt++ {
+		if !c.park(l) {
+			return false
+		}
+		if pred() {
+			return true
+		}
+		if !c.sleep(l, backoff(attempt)) {
+			return false
+		}
+	}
+	return true
+}
+
+// sleep releases l for d and reports false, if Cond/RWCond was closed meanwhile.
+func (c *commonCond) sleep(l sync.Locker, d time.Duration) bool {
+	l.Unlock()
+	defer l.Lock()
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+// WaitForBackoff is same as [Cond.WaitFor], but after each unsuccessful wake (pred is still false) it releases locker
+// for backoff(attempt) and re-checks pred before waiting for the next signal. attempt starts at 1.
+// It paces re-checks of a flapping predicate, e.g. with jittered backoff, to reduce contention. Signals sent during the backoff
+// do not wake the goroutine, but state changes are observed by the re-check. Close interrupts the backoff and returns false.
+func (c *Cond) WaitForBackoff(pred func() bool, backoff func(attempt int) time.Duration) bool {
+	return c.waitForBackoff(c.L, c.predicate("WaitForBackoff", pred), backoff)
+}
+
+// WaitForBackoff is same as [RWCond.WaitFor], but paces re-checks of pred after unsuccessful wakes (see [Cond.WaitForBackoff]).
+func (c *RWCond) WaitForBackoff(pred func() bool, backoff func(attempt int) time.Duration) bool {
+	return c.waitForBackoff(c.rwl, c.predicate("WaitForBackoff", pred), backoff)
+}
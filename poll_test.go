@@ -0,0 +1,126 @@
+package cond_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWaitForPoll(t *testing.T) {
+	c := New(&sync.Mutex{})
+	deadline := time.Now().Add(30 * time.Millisecond)
+	checks := 0
+	c.L.Lock()
+	// Nobody signals: pred depends on time only.
+	ok := c.WaitForPoll(func() bool {
+		checks++
+		return time.Now().After(deadline)
+	}, time.Millisecond, 4*time.Millisecond)
+	c.L.Unlock()
+	if !ok {
+		t.Fatal("want true")
+	}
+	// 1+2+4+4+... ms: about 9 checks for 30ms, far less than busy polling.
+	if checks < 3 || checks > 40 {
+		t.Fatalf("unexpected number of checks %d", checks)
+	}
+	if c.WaitCount() != 0 {
+		t.Fatal("want no waiters")
+	}
+}
+
+func TestWaitForPollSignal(t *testing.T) {
+	c := NewRW(&sync.RWMutex{})
+	x := 0
+	done := make(chan bool)
+	go func() {
+		c.L.RLock()
+		done <- c.WaitForPoll(func() bool { return x == 1 }, time.Hour, time.Hour)
+		c.L.RUnlock()
+	}()
+	waitParked(c, 1)
+	c.L.Lock()
+	x = 1
+	c.L.Unlock()
+	c.Signal(1)
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Fatal("want true")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("signal did not re-check predicate")
+	}
+}
+
+func TestWaitForPollClosed(t *testing.T) {
+	c := New(&sync.Mutex{})
+	done := make(chan bool)
+	go func() {
+		c.L.Lock()
+		done <- c.WaitForPoll(func() bool { return false }, time.Millisecond, time.Millisecond)
+		c.L.Unlock()
+	}()
+	waitParked(c, 1)
+	c.Close()
+	if <-done {
+		t.Fatal("want false for closed Cond")
+	}
+}
+
+func TestWaitForBackoff(t *testing.T) {
+	c := New(&sync.Mutex{})
+	checks := 0
+	var attempts []int
+	done := make(chan bool)
+	go func() {
+		c.L.Lock()
+		done <- c.WaitForBackoff(func() bool {
+			checks++
+			return checks == 5
+		}, func(attempt int) time.Duration {
+			attempts = append(attempts, attempt)
+			return time.Millisecond
+		})
+		c.L.Unlock()
+	}()
+	// Checks: initial, wake, after backoff(1), wake, after backoff(2).
+	for i := 0; i < 2; i++ {
+		waitParked(c, 1)
+		c.Signal(1)
+		for c.WaitCount() != 0 {
+			runtime.Gosched()
+		}
+	}
+	if !<-done {
+		t.Fatal("want true")
+	}
+	if checks != 5 || len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Fatalf("want 5 checks and backoff attempts [1 2], got %d and %v", checks, attempts)
+	}
+}
+
+func TestWaitForBackoffClosed(t *testing.T) {
+	c := NewRW(&sync.RWMutex{})
+	done := make(chan bool)
+	go func() {
+		c.L.RLock()
+		done <- c.WaitForBackoff(func() bool { return false }, func(int) time.Duration { return time.Hour })
+		c.L.RUnlock()
+	}()
+	waitParked(c, 1)
+	c.Signal(1)
+	// Close interrupts the hour long backoff.
+	c.Close()
+	select {
+	case ok := <-done:
+		if ok {
+			t.Fatal("want false for closed RWCond")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not interrupt backoff")
+	}
+}
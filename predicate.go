@@ -0,0 +1,222 @@
+package cond
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+func (c *commonCond) waitFor(l sync.Locker, pred func() bool) bool {
+	for !pred() {
+		if !c.park(l) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *commonCond) waitForErr(l sync.Locker, pred func() (bool, error)) (bool, error) {
+	check := pred
+	if c.opts.panicWrapper {
+		check = func() (bool, error) {
+			defer c.repanic("WaitForErr")
+			return pred()
+		}
+	}
+	for {
+		ok, err := check()
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+		if !c.park(l) {
+			return c.closeError(false, nil)
+		}
+	}
+}
+
+func (c *commonCond) waitForAll(l sync.Locker, preds []func() bool) bool {
+	return c.waitFor(l, c.predicate("WaitForAll", func() bool {
+		for _, pred := range preds {
+			if !pred() {
+				return false
+			}
+		}
+		return true
+	}))
+}
+
+func (c *commonCond) waitForAny(l sync.Locker, preds []func() bool) (int, bool) {
+	index := -1
+	ok := c.waitFor(l, c.predicate("WaitForAny", func() bool {
+		for i, pred := range preds {
+			if pred() {
+				index = i
+				return true
+			}
+		}
+		return false
+	}))
+	if !ok {
+		return -1, false
+	}
+	return index, true
+}
+
+// SetGuard installs pred as the guard used by WaitGuarded. The guard is called with locker locked (RLocked for RWCond).
+// nil removes the guard. WaitGuarded loads the guard once, when it is called, so goroutines already waiting keep their guard
+// and only later calls use pred.
+func (c *commonCond) SetGuard(pred func() bool) {
+	if pred == nil {
+		c.guard.Store(nil)
+	} else {
+		c.guard.Store(&pred)
+	}
+}
+
+func (c *commonCond) waitGuarded(l sync.Locker) bool {
+	if g := c.guard.Load(); g != nil {
+		return c.waitFor(l, c.predicate("WaitGuarded", *g))
+	}
+	return c.park(l)
+}
+
+func (c *commonCond) waitForBudget(l sync.Locker, pred func() bool, maxWakes int) (bool, error) {
+	for wakes := 0; !pred(); wakes++ {
+		if wakes >= maxWakes {
+			return false, ErrBudgetExceeded
+		}
+		if !c.park(l) {
+			return c.closeError(false, nil)
+		}
+	}
+	return true, nil
+}
+
+func (c *commonCond) waitForContext(ctx context.Context, l sync.Locker, pred func() bool) (bool, error) {
+	if pred() {
+		return true, nil
+	}
+	return c.parkUntil(ctx, l, pred)
+}
+
+// parkUntil parks until pred holds after a wake. pred must not hold on entry, so it is called exactly once per wake.
+func (c *commonCond) parkUntil(ctx context.Context, l sync.Locker, pred func() bool) (bool, error) {
+	for {
+		ok, err := c.parkContext(l, ctx, true)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return c.closeError(false, nil)
+		}
+		if pred() {
+			return true, nil
+		}
+	}
+}
+
+func (c *commonCond) waitForTimeout(l sync.Locker, pred func() bool, d time.Duration) (bool, error) {
+	return c.waitForDeadline(l, pred, time.Now().Add(d))
+}
+
+func (c *commonCond) waitForDeadline(l sync.Locker, pred func() bool, deadline time.Time) (bool, error) {
+	if pred() {
+		return true, nil
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	return c.parkUntil(ctx, l, pred)
+}
+
+// WaitFor waits until pred returns true (returns true) or Cond was closed (returns false). pred is called with locker locked.
+// pred is checked before parking, so if it holds on entry, WaitFor returns true without ever Unlocking locker.
+// All predicate based methods (WaitForBudget, WaitForTimeout, WaitForPoll, WaitForBackoff, ...) share this fast path.
+func (c *Cond) WaitFor(pred func() bool) bool {
+	return c.waitFor(c.L, c.predicate("WaitFor", pred))
+}
+
+// WaitGuarded is same as WaitFor(guard) with the guard installed by [commonCond.SetGuard]. Without a guard it is same as [Cond.Wait].
+func (c *Cond) WaitGuarded() bool {
+	return c.waitGuarded(c.L)
+}
+
+// WaitForErr is same as [Cond.WaitFor], but pred may fail: the first non-nil error of pred aborts the wait and is returned
+// together with false. Returns false and nil, if Cond was closed (false and [ErrClosed] with [WithErrorOnClose]).
+func (c *Cond) WaitForErr(pred func() (bool, error)) (bool, error) {
+	return c.waitForErr(c.L, pred)
+}
+
+// WaitForAll is same as [Cond.WaitFor], but waits until every pred holds. Predicates are checked in order on every wake
+// and checking stops at the first one, which does not hold. WaitForAll without predicates returns true immediately.
+func (c *Cond) WaitForAll(preds ...func() bool) bool {
+	return c.waitForAll(c.L, preds)
+}
+
+// WaitForAny is same as [Cond.WaitFor], but waits until any pred holds and returns the index of the first one, which holds, and true.
+// Returns -1 and false, if Cond was closed. WaitForAny without predicates waits until Cond is closed.
+func (c *Cond) WaitForAny(preds ...func() bool) (index int, ok bool) {
+	return c.waitForAny(c.L, preds)
+}
+
+// WaitForBudget is same as [Cond.WaitFor], but gives up after maxWakes unsuccessful wakes and returns false and [ErrBudgetExceeded].
+// Returns true and nil as soon as pred holds regardless of budget. Returns false and nil, if Cond was closed (false and [ErrClosed] with [WithErrorOnClose]).
+func (c *Cond) WaitForBudget(pred func() bool, maxWakes int) (bool, error) {
+	return c.waitForBudget(c.L, c.predicate("WaitForBudget", pred), maxWakes)
+}
+
+// WaitFor waits until pred returns true (returns true) or RWCond was closed (returns false). pred is called with locker RLocked.
+// If pred holds on entry, it returns true without ever RUnlocking locker (see [Cond.WaitFor]).
+func (c *RWCond) WaitFor(pred func() bool) bool {
+	return c.waitFor(c.rwl, c.predicate("WaitFor", pred))
+}
+
+// WaitGuarded is same as WaitFor(guard) with the guard installed by [commonCond.SetGuard]. Without a guard it is same as [RWCond.Wait].
+func (c *RWCond) WaitGuarded() bool {
+	return c.waitGuarded(c.rwl)
+}
+
+// WaitForErr is same as [Cond.WaitForErr], but pred is called with locker RLocked.
+func (c *RWCond) WaitForErr(pred func() (bool, error)) (bool, error) {
+	return c.waitForErr(c.rwl, pred)
+}
+
+// WaitForAll is same as [Cond.WaitForAll], but predicates are called with locker RLocked.
+func (c *RWCond) WaitForAll(preds ...func() bool) bool {
+	return c.waitForAll(c.rwl, preds)
+}
+
+// WaitForAny is same as [Cond.WaitForAny], but predicates are called with locker RLocked.
+func (c *RWCond) WaitForAny(preds ...func() bool) (index int, ok bool) {
+	return c.waitForAny(c.rwl, preds)
+}
+
+// WaitForBudget is same as [RWCond.WaitFor], but gives up after maxWakes unsuccessful wakes and returns false and [ErrBudgetExceeded].
+// Returns true and nil as soon as pred holds regardless of budget. Returns false and nil, if RWCond was closed (false and [ErrClosed] with [WithErrorOnClose]).
+func (c *RWCond) WaitForBudget(pred func() bool, maxWakes int) (bool, error) {
+	return c.waitForBudget(c.rwl, c.predicate("WaitForBudget", pred), maxWakes)
+}
+
+// WaitForTimeout is same as [Cond.WaitFor], but gives up when d elapses and returns false and context.DeadlineExceeded.
+// d bounds the total time of the call, so every re-park only waits for the remaining time.
+func (c *Cond) WaitForTimeout(pred func() bool, d time.Duration) (bool, error) {
+	return c.waitForTimeout(c.L, c.predicate("WaitForTimeout", pred), d)
+}
+
+// WaitForTimeout is same as [RWCond.WaitFor], but gives up when d elapses and returns false and context.DeadlineExceeded.
+// d bounds the total time of the call, so every re-park only waits for the remaining time.
+func (c *RWCond) WaitForTimeout(pred func() bool, d time.Duration) (bool, error) {
+	return c.waitForTimeout(c.rwl, c.predicate("WaitForTimeout", pred), d)
+}
+
+// WaitForDeadline is same as [Cond.WaitForTimeout], but gives up at deadline. A deadline in the past still checks pred once.
+func (c *Cond) WaitForDeadline(pred func() bool, deadline time.Time) (bool, error) {
+	return c.waitForDeadline(c.L, c.predicate("WaitForDeadline", pred), deadline)
+}
+
+// WaitForDeadline is same as [RWCond.WaitForTimeout], but gives up at deadline. A deadline in the past still checks pred once.
+func (c *RWCond) WaitForDeadline(pred func() bool, deadline time.Time) (bool, error) {
+	return c.waitForDeadline(c.rwl, c.predicate("WaitForDeadline", pred), deadline)
+}
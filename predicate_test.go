@@ -0,0 +1,409 @@
+package cond_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+// waitParked spins until c has at least n waiting goroutines.
+func waitParked(c interface{ WaitCount() int }, n int) {
+	for c.WaitCount() < n {
+		runtime.Gosched()
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	c := New(&sync.Mutex{})
+	x := 0
+	done := make(chan bool)
+	go func() {
+		c.L.Lock()
+		done <- c.WaitFor(func() bool { return x == 3 })
+		c.L.Unlock()
+	}()
+	for i := 1; i <= 3; i++ {
+		waitParked(c, 1)
+		c.L.Lock()
+		x = i
+		c.L.Unlock()
+		c.Signal(1)
+	}
+	if !<-done {
+		t.Fatal("want true")
+	}
+}
+
+func TestWaitForClosed(t *testing.T) {
+	c := New(&sync.Mutex{})
+	done := make(chan bool)
+	go func() {
+		c.L.Lock()
+		done <- c.WaitFor(func() bool { return false })
+		c.L.Unlock()
+	}()
+	waitParked(c, 1)
+	c.Close()
+	if <-done {
+		t.Fatal("want false for closed Cond")
+	}
+}
+
+func TestWaitForErr(t *testing.T) {
+	c := New(&sync.Mutex{})
+	errCorrupt := errors.New("corrupt")
+	checks := 0
+	done := make(chan error)
+	go func() {
+		c.L.Lock()
+		defer c.L.Unlock()
+		ok, err := c.WaitForErr(func() (bool, error) {
+			checks++
+			if checks == 3 {
+				return false, errCorrupt
+			}
+			return false, nil
+		})
+		if ok {
+			err = errors.New("want false")
+		}
+		done <- err
+	}()
+	for want := 1; want <= 2; want++ {
+		// The goroutine releases locker only by parking, so once it made want checks, the broadcast wakes it.
+		c.L.Lock()
+		for checks != want {
+			c.L.Unlock()
+			runtime.Gosched()
+			c.L.Lock()
+		}
+		c.L.Unlock()
+		c.Broadcast()
+	}
+	if err := <-done; err != errCorrupt {
+		t.Fatalf("want error of the 3rd check, got %v", err)
+	}
+	if checks != 3 {
+		t.Fatalf("want 3 checks, got %d", checks)
+	}
+
+	c.Close()
+	c.L.Lock()
+	ok, err := c.WaitForErr(func() (bool, error) { return false, nil })
+	c.L.Unlock()
+	if ok || err != nil {
+		t.Fatalf("want false and nil on close, got %v and %v", ok, err)
+	}
+}
+
+func TestWaitForAll(t *testing.T) {
+	c := New(&sync.Mutex{})
+	var a, b bool
+	done := make(chan bool)
+	go func() {
+		c.L.Lock()
+		defer c.L.Unlock()
+		done <- c.WaitForAll(func() bool { return a }, func() bool { return b })
+	}()
+	waitParked(c, 1)
+	c.L.Lock()
+	a = true
+	c.L.Unlock()
+	c.Broadcast()
+	// Only one predicate holds, so the goroutine parks again.
+	waitParked(c, 1)
+	c.L.Lock()
+	b = true
+	c.L.Unlock()
+	c.Broadcast()
+	if !<-done {
+		t.Fatal("want true")
+	}
+	c.L.Lock()
+	ok := c.WaitForAll()
+	c.L.Unlock()
+	if !ok {
+		t.Fatal("want true without predicates")
+	}
+}
+
+func TestWaitForAny(t *testing.T) {
+	c := New(&sync.Mutex{})
+	var a, b bool
+	type res struct {
+		index int
+		ok    bool
+	}
+	done := make(chan res)
+	go func() {
+		c.L.Lock()
+		defer c.L.Unlock()
+		i, ok := c.WaitForAny(func() bool { return a }, func() bool { return b })
+		done <- res{i, ok}
+	}()
+	waitParked(c, 1)
+	c.Broadcast()
+	// Neither predicate holds, so the goroutine parks again.
+	waitParked(c, 1)
+	c.L.Lock()
+	b = true
+	c.L.Unlock()
+	c.Broadcast()
+	if r := <-done; r.index != 1 || !r.ok {
+		t.Fatalf("want 1 and true, got %d and %v", r.index, r.ok)
+	}
+
+	go func() {
+		c.L.Lock()
+		defer c.L.Unlock()
+		i, ok := c.WaitForAny(func() bool { return a })
+		done <- res{i, ok}
+	}()
+	waitParked(c, 1)
+	c.Close()
+	if r := <-done; r.index != -1 || r.ok {
+		t.Fatalf("want -1 and false on close, got %d and %v", r.index, r.ok)
+	}
+}
+
+func TestWaitForBudget(t *testing.T) {
+	c := New(&sync.Mutex{})
+	type result struct {
+		ok  bool
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		c.L.Lock()
+		ok, err := c.WaitForBudget(func() bool { return false }, 3)
+		c.L.Unlock()
+		done <- result{ok, err}
+	}()
+	for i := 0; i < 3; i++ {
+		waitParked(c, 1)
+		c.Signal(1)
+	}
+	r := <-done
+	if r.ok || r.err != ErrBudgetExceeded {
+		t.Fatalf("want false and ErrBudgetExceeded, got %v and %v", r.ok, r.err)
+	}
+
+	c.L.Lock()
+	ok, err := c.WaitForBudget(func() bool { return true }, 0)
+	c.L.Unlock()
+	if !ok || err != nil {
+		t.Fatalf("want true and nil, got %v and %v", ok, err)
+	}
+}
+
+func TestRWCondWaitFor(t *testing.T) {
+	c := NewRW(&sync.RWMutex{})
+	x := 0
+	done := make(chan bool)
+	go func() {
+		c.L.RLock()
+		done <- c.WaitFor(func() bool { return x == 1 })
+		c.L.RUnlock()
+	}()
+	waitParked(c, 1)
+	c.L.Lock()
+	x = 1
+	c.L.Unlock()
+	c.Broadcast()
+	if !<-done {
+		t.Fatal("want true")
+	}
+}
+
+func TestWaitForTimeout(t *testing.T) {
+	c := New(&sync.Mutex{})
+	x := 0
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		c.L.Lock()
+		x = 1
+		c.L.Unlock()
+		c.Broadcast()
+	}()
+	c.L.Lock()
+	ok, err := c.WaitForTimeout(func() bool { return x == 1 }, time.Second)
+	c.L.Unlock()
+	if !ok || err != nil {
+		t.Fatalf("want true and nil, got %v and %v", ok, err)
+	}
+}
+
+func TestWaitForTimeoutExpired(t *testing.T) {
+	c := New(&sync.Mutex{})
+	x := 0
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		// Flapping wakes must not reset the deadline.
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				c.Broadcast()
+			}
+		}
+	}()
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		c.L.Lock()
+		x = 1
+		c.L.Unlock()
+	}()
+	start := time.Now()
+	c.L.Lock()
+	ok, err := c.WaitForTimeout(func() bool { return x == 1 }, 20*time.Millisecond)
+	c.L.Unlock()
+	if ok || err != context.DeadlineExceeded {
+		t.Fatalf("want false and context.DeadlineExceeded, got %v and %v", ok, err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("deadline was reset by wakes, elapsed %v", elapsed)
+	}
+}
+
+func TestWaitForTimeoutPredicateCalls(t *testing.T) {
+	c := New(&sync.Mutex{})
+	c.Close()
+	calls := 0
+	c.L.Lock()
+	c.WaitForTimeout(func() bool { calls++; return false }, time.Second)
+	c.L.Unlock()
+	if calls != 1 {
+		t.Fatalf("want predicate called once, got %d", calls)
+	}
+}
+
+func TestWaitForDeadline(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		ready time.Duration
+		want  bool
+	}{
+		{"under", 10 * time.Millisecond, true},
+		{"over", 200 * time.Millisecond, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := New(&sync.Mutex{})
+			x := 0
+			go func() {
+				time.Sleep(tc.ready)
+				c.L.Lock()
+				x = 1
+				c.L.Unlock()
+				c.Broadcast()
+			}()
+			deadline := time.Now().Add(100 * time.Millisecond)
+			c.L.Lock()
+			ok, err := c.WaitForDeadline(func() bool { return x == 1 }, deadline)
+			c.L.Unlock()
+			if tc.want {
+				if !ok || err != nil {
+					t.Fatalf("want true and nil, got %v and %v", ok, err)
+				}
+				return
+			}
+			if ok || err != context.DeadlineExceeded {
+				t.Fatalf("want false and context.DeadlineExceeded, got %v and %v", ok, err)
+			}
+			if time.Now().Before(deadline) {
+				t.Fatal("returned before deadline")
+			}
+		})
+	}
+}
+
+// noUnlockLocker fails the test, if it is unlocked.
+type noUnlockLocker struct {
+	sync.Mutex
+	t *testing.T
+}
+
+func (l *noUnlockLocker) Unlock() {
+	l.t.Error("locker was released although predicate held on entry")
+	l.Mutex.Unlock()
+}
+
+func TestWaitForFastPath(t *testing.T) {
+	l := &noUnlockLocker{t: t}
+	c := New(l)
+	ready := func() bool { return true }
+	l.Mutex.Lock()
+	defer l.Mutex.Unlock()
+	if !c.WaitFor(ready) {
+		t.Fatal("WaitFor: want true")
+	}
+	if ok, err := c.WaitForBudget(ready, 0); !ok || err != nil {
+		t.Fatal("WaitForBudget: want true")
+	}
+	if ok, err := c.WaitForTimeout(ready, time.Nanosecond); !ok || err != nil {
+		t.Fatal("WaitForTimeout: want true")
+	}
+	if ok, err := c.WaitForDeadline(ready, time.Time{}); !ok || err != nil {
+		t.Fatal("WaitForDeadline: want true")
+	}
+	if !c.WaitForPoll(ready, time.Nanosecond, time.Nanosecond) {
+		t.Fatal("WaitForPoll: want true")
+	}
+	if !c.WaitForBackoff(ready, func(int) time.Duration { return 0 }) {
+		t.Fatal("WaitForBackoff: want true")
+	}
+	// The fast path does not depend on Cond state.
+	c.Close()
+	if !c.WaitFor(ready) {
+		t.Fatal("WaitFor on closed Cond: want true")
+	}
+}
+
+func TestWaitGuarded(t *testing.T) {
+	c := New(&sync.Mutex{})
+	x := 0
+	c.SetGuard(func() bool { return x >= 2 })
+	// Same sequence as TestWaitFor: WaitGuarded re-parks until the guard holds.
+	done := make(chan bool)
+	go func() {
+		c.L.Lock()
+		done <- c.WaitGuarded()
+		c.L.Unlock()
+	}()
+	for i := 1; i <= 2; i++ {
+		waitParked(c, 1)
+		if i == 1 {
+			// The waiting goroutine keeps the guard it loaded.
+			c.SetGuard(func() bool { return true })
+		}
+		c.L.Lock()
+		x = i
+		c.L.Unlock()
+		c.Signal(1)
+	}
+	if !<-done {
+		t.Fatal("want true")
+	}
+
+	c.L.Lock()
+	if !c.WaitGuarded() {
+		t.Fatal("want fast path for satisfied guard")
+	}
+	c.L.Unlock()
+
+	c.Close()
+	c.SetGuard(func() bool { return false })
+	c.L.Lock()
+	defer c.L.Unlock()
+	if c.WaitGuarded() {
+		t.Fatal("want false for closed Cond")
+	}
+}
@@ -0,0 +1,54 @@
+package cond
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// waiterQueue bounds parked goroutines of one Cond/RWCond (see [WithWaiterQueue]).
+type waiterQueue struct {
+	slots  chan struct{}
+	queued atomic.Int64
+}
+
+func (q *waiterQueue) tryAcquire() bool {
+	select {
+	case q.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *waiterQueue) release() {
+	<-q.slots
+}
+
+// wait releases l and blocks until a parking slot frees up (returns true and nil), done is closed (false and nil)
+// or ctx is cancelled (false and ctx.Err()), and Locks l again. The slot is passed on at once: the goroutine does not park,
+// because signals sent while it was queued were not delivered to it. Queued goroutines are released in FIFO order.
+func (q *waiterQueue) wait(l sync.Locker, ctx context.Context, done <-chan struct{}) (bool, error) {
+	q.queued.Add(1)
+	defer q.queued.Add(-1)
+	l.Unlock()
+	defer l.Lock()
+	select {
+	case q.slots <- struct{}{}:
+		q.release()
+		return true, nil
+	case <-done:
+		return false, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// QueuedWaiters returns current number of goroutines queued by [WithWaiterQueue], i.e. waiting for a parking slot.
+// They are not counted by WaitCount and are not awoken by signals.
+func (c *commonCond) QueuedWaiters() int {
+	if c.queue == nil {
+		return 0
+	}
+	return int(c.queue.queued.Load())
+}
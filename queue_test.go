@@ -0,0 +1,66 @@
+package cond_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+func waitQueued(c *Cond, parked, queued int) {
+	for c.WaitCount() != parked || c.QueuedWaiters() != queued {
+		runtime.Gosched()
+	}
+}
+
+func TestWithWaiterQueue(t *testing.T) {
+	c := New(&sync.Mutex{}, WithWaiterQueue(2))
+	ready := false
+	n := 5
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.L.Lock()
+			defer c.L.Unlock()
+			if !c.WaitFor(func() bool { return ready }) {
+				t.Error("want true")
+			}
+		}()
+	}
+	waitQueued(c, 2, 3)
+
+	// A woken goroutine frees its slot, so a queued one re-checks and takes it, while the woken one queues.
+	c.Signal(1)
+	waitQueued(c, 2, 3)
+
+	c.L.Lock()
+	ready = true
+	c.L.Unlock()
+	c.Broadcast()
+	wg.Wait()
+	if c.WaitCount() != 0 || c.QueuedWaiters() != 0 {
+		t.Fatalf("want no waiters, got %d parked and %d queued", c.WaitCount(), c.QueuedWaiters())
+	}
+}
+
+func TestWithWaiterQueueClose(t *testing.T) {
+	c := New(&sync.Mutex{}, WithWaiterQueue(1))
+	results := make(chan bool, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			c.L.Lock()
+			defer c.L.Unlock()
+			results <- c.Wait()
+		}()
+	}
+	waitQueued(c, 1, 2)
+	c.Close()
+	for i := 0; i < 3; i++ {
+		if <-results {
+			t.Fatal("want false on close")
+		}
+	}
+}
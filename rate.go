@@ -0,0 +1,77 @@
+package cond
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// rateBucketWidth is the granularity of [commonCond.SignalRate].
+	rateBucketWidth = 100 * time.Millisecond
+	// rateBuckets bounds memory of [WithSignalRate] to ~2KB and the longest window to (rateBuckets-1)*rateBucketWidth.
+	rateBuckets = 128
+)
+
+type rateBucket struct {
+	epoch atomic.Int64
+	count atomic.Int64
+}
+
+// signalRate is a ring of per-rateBucketWidth signal counters.
+type signalRate struct {
+	start   time.Time
+	buckets [rateBuckets]rateBucket
+}
+
+func newSignalRate() *signalRate {
+	return &signalRate{start: time.Now()}
+}
+
+func (r *signalRate) record() {
+	epoch := int64(time.Since(r.start) / rateBucketWidth)
+	b := &r.buckets[epoch%rateBuckets]
+	if old := b.epoch.Load(); old != epoch && b.epoch.CompareAndSwap(old, epoch) {
+		b.count.Store(0)
+	}
+	b.count.Add(1)
+}
+
+func (r *signalRate) rate(window time.Duration) float64 {
+	now := time.Since(r.start)
+	epoch := int64(now / rateBucketWidth)
+	k := int64((window + rateBucketWidth - 1) / rateBucketWidth)
+	k = min(max(k, 1), rateBuckets-1, epoch+1)
+	var sum int64
+	for e := epoch - k + 1; e <= epoch; e++ {
+		b := &r.buckets[e%rateBuckets]
+		if b.epoch.Load() == e {
+			sum += b.count.Load()
+		}
+	}
+	// The current bucket is only partially elapsed.
+	elapsed := time.Duration(k-1)*rateBucketWidth + now - time.Duration(epoch)*rateBucketWidth
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(sum) / elapsed.Seconds()
+}
+
+// SignalRate returns signals per second over the last window enabled by [WithSignalRate] (0 without the option).
+// Every Signal, SignalWithContext and broadcast, which is delivered, counts as one signal regardless of how many goroutines it wakes.
+// Heartbeats of [WithHeartbeat] are not counted.
+// Signals are counted in 100ms buckets, so window is rounded up to 100ms and capped at 12.7s, and the rate is approximate
+// (a signal racing with a bucket reset may be lost). In exchange memory is fixed (~2KB) and recording costs two atomics and time.Now.
+func (c *commonCond) SignalRate(window time.Duration) float64 {
+	if c.rate == nil {
+		return 0
+	}
+	return c.rate.rate(window)
+}
+
+// delivered is called by every delivered Signal, SignalWithContext and broadcast.
+func (c *commonCond) delivered() {
+	c.seq.Add(1)
+	if c.rate != nil {
+		c.rate.record()
+	}
+}
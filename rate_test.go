@@ -0,0 +1,45 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestSignalRate(t *testing.T) {
+	c := New(&sync.Mutex{}, WithSignalRate())
+	// 200 signals per second for 600ms.
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+	for deadline := time.Now().Add(600 * time.Millisecond); time.Now().Before(deadline); {
+		<-ticker.C
+		c.Signal(1)
+	}
+	if r := c.SignalRate(400 * time.Millisecond); r < 100 || r > 300 {
+		t.Fatalf("want ~200 signals per second, got %v", r)
+	}
+	// The window is capped by the elapsed time, so it covers all signals.
+	if r := c.SignalRate(time.Hour); r < 20 || r > 300 {
+		t.Fatalf("want capped window to include all signals, got %v", r)
+	}
+
+	if New(&sync.Mutex{}).SignalRate(time.Second) != 0 {
+		t.Fatal("want 0 without the option")
+	}
+}
+
+func TestSignalRateIdle(t *testing.T) {
+	c := New(&sync.Mutex{}, WithSignalRate())
+	for i := 0; i < 100; i++ {
+		c.Broadcast()
+	}
+	if c.SignalRate(time.Second) == 0 {
+		t.Fatal("want non-zero rate after a burst")
+	}
+	time.Sleep(300 * time.Millisecond)
+	if r := c.SignalRate(100 * time.Millisecond); r != 0 {
+		t.Fatalf("want 0 for idle window, got %v", r)
+	}
+}
@@ -0,0 +1,51 @@
+package cond
+
+import "context"
+
+// WaitReady starts a goroutine, which waits for signal like Wait, and returns immediately.
+// parked is closed as soon as the goroutine is counted by WaitCount, so a subsequent Signal(1) is guaranteed to wake it
+// (or if Cond/RWCond is closed and the goroutine never parks). result delivers Wait's result and is buffered, so it may be ignored.
+// It spawns a goroutine instead of blocking, because a blocking call can not hand out parked before it returns.
+// The goroutine does not use associated locker, so WaitReady may be called with or without locker held.
+// It is meant for tests, which need to know that a waiter is parked before signalling, without sleeps.
+func (c *commonCond) WaitReady() (parked <-chan struct{}, result <-chan bool) {
+	l := &readyLocker{parked: make(chan struct{})}
+	res := make(chan bool, 1)
+	go func() {
+		ok := c.park(l)
+		if !l.unlocked {
+			close(l.parked)
+		}
+		res <- ok
+	}()
+	return l.parked, res
+}
+
+// readyLocker is a no-op locker, which reports parking: wake counts the goroutine right before Unlock.
+type readyLocker struct {
+	parked   chan struct{}
+	unlocked bool
+}
+
+func (l *readyLocker) Lock() {}
+
+func (l *readyLocker) Unlock() {
+	l.unlocked = true
+	close(l.parked)
+}
+
+// WaitCancelable starts a goroutine, which waits for signal like WaitWithContext, and returns immediately.
+// Calling cancel unparks just that goroutine, so result delivers false, unless it was already awoken (then cancel does nothing).
+// result also delivers false, if Cond/RWCond is closed. Unlike blocking Wait methods it does not use associated locker,
+// so the caller does not hold locker, when result is delivered, and must acquire it itself to inspect shared state.
+// cancel may be called several times and from any goroutine. result is buffered, so it may be ignored.
+func (c *commonCond) WaitCancelable() (result <-chan bool, cancel func()) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	res := make(chan bool, 1)
+	go func() {
+		defer cancelCtx()
+		ok, _ := c.parkContext(nopLocker{}, ctx, true)
+		res <- ok
+	}()
+	return res, cancelCtx
+}
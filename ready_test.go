@@ -0,0 +1,70 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWaitReady(t *testing.T) {
+	c := New(&sync.Mutex{})
+	for i := 0; i < 100; i++ {
+		parked, result := c.WaitReady()
+		<-parked
+		if n := c.Signal(1); n != 1 {
+			t.Fatalf("want 1 awoken after parked, got %d", n)
+		}
+		if !<-result {
+			t.Fatal("want true")
+		}
+	}
+
+	parked, result := c.WaitReady()
+	<-parked
+	c.Close()
+	if <-result {
+		t.Fatal("want false for closed Cond")
+	}
+
+	parked, result = c.WaitReady()
+	<-parked
+	if <-result {
+		t.Fatal("want false for closed Cond")
+	}
+}
+
+func TestWaitCancelable(t *testing.T) {
+	c := New(&sync.Mutex{})
+	var results []<-chan bool
+	var cancels []func()
+	for i := 0; i < 3; i++ {
+		result, cancel := c.WaitCancelable()
+		results = append(results, result)
+		cancels = append(cancels, cancel)
+	}
+	waitParked(c, 3)
+
+	cancels[1]()
+	if <-results[1] {
+		t.Fatal("want false for cancelled wait")
+	}
+	if n := c.WaitCount(); n != 2 {
+		t.Fatalf("want 2 waiters left, got %d", n)
+	}
+	for _, i := range []int{0, 2} {
+		select {
+		case <-results[i]:
+			t.Fatalf("waiter %d returned after cancelling another one", i)
+		default:
+		}
+	}
+	c.Broadcast()
+	for _, i := range []int{0, 2} {
+		if !<-results[i] {
+			t.Fatalf("want true for waiter %d", i)
+		}
+		// Cancelling a finished wait does nothing.
+		cancels[i]()
+	}
+}
@@ -0,0 +1,66 @@
+package cond
+
+import "sync"
+
+// Reason describes why a wait ended.
+type Reason int
+
+const (
+	// ReasonSignal means that the goroutine was individually awoken by Signal or SignalWithContext.
+	ReasonSignal Reason = iota + 1
+	// ReasonBroadcast means that the goroutine was awoken together with others by Broadcast (or Signal with n <= 0).
+	ReasonBroadcast
+	// ReasonClosed means that Cond/RWCond was closed.
+	ReasonClosed
+)
+
+func (r Reason) String() string {
+	switch r {
+	case ReasonSignal:
+		return "signal"
+	case ReasonBroadcast:
+		return "broadcast"
+	case ReasonClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// waitReason parks and tells a broadcast from a signal by broadcast counters: a broadcast, which wakes the goroutine,
+// completes its swap after the goroutine starts waiting, so it must have started (broadcasting) after the last completed
+// one (broadcasts) observed before parking. A broadcast running concurrently with a signal may be reported instead of the signal.
+func (c *commonCond) waitReason(l sync.Locker) (Reason, uint64) {
+	before := c.broadcasts.Load()
+	ok, epoch := c.parkEpoch(l)
+	if !ok {
+		return ReasonClosed, 0
+	}
+	if c.broadcasting.Load() > before {
+		return ReasonBroadcast, epoch
+	}
+	return ReasonSignal, epoch
+}
+
+// WaitReason is same as [Cond.Wait], but reports why it was unblocked. If a broadcast races with a signal, it may report ReasonBroadcast.
+func (c *Cond) WaitReason() Reason {
+	r, _ := c.waitReason(c.L)
+	return r
+}
+
+// WaitEpoch is same as [Cond.WaitReason], but also reports the epoch of [commonCond.SignalEpoch], which woke the goroutine,
+// or 0, if it was woken otherwise.
+func (c *Cond) WaitEpoch() (Reason, uint64) {
+	return c.waitReason(c.L)
+}
+
+// WaitReason is same as [RWCond.Wait], but reports why it was unblocked. If a broadcast races with a signal, it may report ReasonBroadcast.
+func (c *RWCond) WaitReason() Reason {
+	r, _ := c.waitReason(c.rwl)
+	return r
+}
+
+// WaitEpoch is same as [RWCond.WaitReason], but also reports the epoch of [commonCond.SignalEpoch], which woke the goroutine,
+// or 0, if it was woken otherwise.
+func (c *RWCond) WaitEpoch() (Reason, uint64) {
+	return c.waitReason(c.rwl)
+}
@@ -0,0 +1,114 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWaitReason(t *testing.T) {
+	c := New(&sync.Mutex{})
+	wait := func() <-chan Reason {
+		ch := make(chan Reason, 1)
+		go func() {
+			c.L.Lock()
+			ch <- c.WaitReason()
+			c.L.Unlock()
+		}()
+		waitParked(c, 1)
+		return ch
+	}
+
+	ch := wait()
+	c.Signal(1)
+	if r := <-ch; r != ReasonSignal {
+		t.Fatalf("want %v, got %v", ReasonSignal, r)
+	}
+
+	ch = wait()
+	c.Broadcast()
+	if r := <-ch; r != ReasonBroadcast {
+		t.Fatalf("want %v, got %v", ReasonBroadcast, r)
+	}
+
+	ch = wait()
+	c.Signal(0)
+	if r := <-ch; r != ReasonBroadcast {
+		t.Fatalf("Signal(0): want %v, got %v", ReasonBroadcast, r)
+	}
+
+	ch = wait()
+	c.Signal(1)
+	if r := <-ch; r != ReasonSignal {
+		t.Fatalf("signal after broadcasts: want %v, got %v", ReasonSignal, r)
+	}
+
+	ch = wait()
+	c.Close()
+	if r := <-ch; r != ReasonClosed {
+		t.Fatalf("want %v, got %v", ReasonClosed, r)
+	}
+	c.L.Lock()
+	if r := c.WaitReason(); r != ReasonClosed {
+		t.Fatalf("already closed: want %v, got %v", ReasonClosed, r)
+	}
+	c.L.Unlock()
+}
+
+func TestSignalEpoch(t *testing.T) {
+	c := New(&sync.Mutex{})
+	type result struct {
+		reason Reason
+		epoch  uint64
+	}
+	results := make(chan result, 4)
+	wait := func(n int) {
+		for i := 0; i < n; i++ {
+			go func() {
+				c.L.Lock()
+				r, epoch := c.WaitEpoch()
+				c.L.Unlock()
+				results <- result{r, epoch}
+			}()
+		}
+		waitParked(c, n)
+	}
+
+	wait(3)
+	if woken, epoch := c.SignalEpoch(2); woken != 2 || epoch != 1 {
+		t.Fatalf("want 2 and epoch 1, got %d and %d", woken, epoch)
+	}
+	for i := 0; i < 2; i++ {
+		if r := <-results; r != (result{ReasonSignal, 1}) {
+			t.Fatalf("want signal with epoch 1, got %+v", r)
+		}
+	}
+	if woken, epoch := c.SignalEpoch(1); woken != 1 || epoch != 2 {
+		t.Fatalf("want 1 and epoch 2, got %d and %d", woken, epoch)
+	}
+	if r := <-results; r != (result{ReasonSignal, 2}) {
+		t.Fatalf("want signal with epoch 2, got %+v", r)
+	}
+
+	wait(1)
+	c.Broadcast()
+	if r := <-results; r != (result{ReasonBroadcast, 0}) {
+		t.Fatalf("want broadcast without epoch, got %+v", r)
+	}
+	if woken, epoch := c.SignalEpoch(1); woken != 0 || epoch != 3 {
+		t.Fatalf("no waiters: want 0 and epoch 3, got %d and %d", woken, epoch)
+	}
+
+	// SignalEpoch does not wait for woken goroutines, so it may be called with locker held.
+	wait(1)
+	c.L.Lock()
+	woken, epoch := c.SignalEpoch(1)
+	c.L.Unlock()
+	if woken != 1 || epoch != 4 {
+		t.Fatalf("want 1 and epoch 4, got %d and %d", woken, epoch)
+	}
+	if r := <-results; r != (result{ReasonSignal, 4}) {
+		t.Fatalf("want signal with epoch 4, got %+v", r)
+	}
+}
@@ -0,0 +1,152 @@
+package cond_test
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestRWCondWaitUpgrade(t *testing.T) {
+	c := NewRW(&sync.RWMutex{})
+	n := 100
+	var writers atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.L.RLock()
+			c.WaitUpgrade()
+			if writers.Add(1) != 1 {
+				t.Error("more than one upgrader holds the write lock")
+			}
+			time.Sleep(time.Microsecond)
+			writers.Add(-1)
+			c.L.Unlock()
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case <-done:
+			if c.UpgradeWaiters() != 0 {
+				t.Fatalf("want 0 upgrade waiters, got %d", c.UpgradeWaiters())
+			}
+			return
+		case <-ticker.C:
+			c.Broadcast()
+		case <-deadline:
+			t.Fatal("upgraders deadlocked")
+		}
+	}
+}
+
+func TestRWCondWaitUpgradeClosed(t *testing.T) {
+	c := NewRW(&sync.RWMutex{})
+	c.Close()
+
+	c.L.RLock()
+	if c.WaitUpgrade() {
+		t.Fatal("want false for closed RWCond")
+	}
+	if c.L.TryRLock() {
+		t.Fatal("want write lock to be held")
+	}
+	c.L.Unlock()
+}
+
+func TestRWCondWaitUpgradeFor(t *testing.T) {
+	c := NewRW(&sync.RWMutex{})
+	tokens := 0
+	n := 50
+	var consumed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.L.RLock()
+			if c.WaitUpgradeFor(func() bool { return tokens > 0 }) {
+				tokens--
+				consumed.Add(1)
+			}
+			if tokens < 0 {
+				t.Error("token consumed twice")
+			}
+			c.L.Unlock()
+		}()
+	}
+
+	for produced := 0; produced < n; produced += 5 {
+		c.L.Lock()
+		tokens += 5
+		c.L.Unlock()
+		c.Broadcast()
+		for consumed.Load() < int32(produced+5) {
+			c.Broadcast()
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wg.Wait()
+	if tokens != 0 {
+		t.Fatalf("want all tokens consumed, %d left", tokens)
+	}
+
+	c.L.RLock()
+	if !c.WaitUpgradeFor(func() bool { return true }) {
+		t.Fatal("want true for satisfied predicate")
+	}
+	c.L.Unlock()
+}
+
+func TestRWCondWaitCountByClass(t *testing.T) {
+	c := NewRW(&sync.RWMutex{})
+	readers, writers := 4, 3
+	var wg sync.WaitGroup
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.L.RLock()
+			c.Wait()
+			c.L.RUnlock()
+		}()
+	}
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.L.RLock()
+			c.WaitUpgrade()
+			c.L.Unlock()
+		}()
+	}
+	waitParked(c, readers+writers)
+	for c.WaitCountWriters() < writers {
+		runtime.Gosched()
+	}
+	if r, w := c.WaitCountReaders(), c.WaitCountWriters(); r != readers || w != writers {
+		t.Fatalf("want %d readers and %d writers, got %d and %d", readers, writers, r, w)
+	}
+	if c.WaitCountReaders()+c.WaitCountWriters() != c.WaitCount() {
+		t.Fatal("want readers + writers == WaitCount")
+	}
+	c.Broadcast()
+	wg.Wait()
+	if r, w := c.WaitCountReaders(), c.WaitCountWriters(); r != 0 || w != 0 {
+		t.Fatalf("want 0 readers and 0 writers, got %d and %d", r, w)
+	}
+}
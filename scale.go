@@ -0,0 +1,58 @@
+package cond
+
+import "time"
+
+// scaleDebounce is how long the scale goroutine waits after a wait count change before calling scale, so bursts of changes
+// result in a single call.
+const scaleDebounce = 10 * time.Millisecond
+
+type scalePolicy struct {
+	min, max int
+	scale    func(waiting int) int
+}
+
+// kickScale notifies the scale goroutine about wait count change without blocking.
+func (c *commonCond) kickScale() {
+	select {
+	case c.scaleKick <- struct{}{}:
+	default:
+	}
+}
+
+// runScale calls policy.scale with debounced WaitCount() changes and stores clamped result. It exits on Close.
+// The initial count is evaluated by init, so ScaleTarget is set once constructor returns.
+func (c *commonCond) runScale(p scalePolicy) {
+	last := 0
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.scaleKick:
+		}
+		select {
+		case <-c.done:
+			return
+		case <-time.After(scaleDebounce):
+		}
+		// Changes during debounce are covered by this evaluation.
+		select {
+		case <-c.scaleKick:
+		default:
+		}
+		n := c.WaitCount()
+		if n == last {
+			continue
+		}
+		last = n
+		c.scaleTarget.Store(int64(p.clamp(p.scale(n))))
+	}
+}
+
+func (p scalePolicy) clamp(desired int) int {
+	return min(max(desired, p.min), p.max)
+}
+
+// ScaleTarget returns the last desired worker count requested by [WithScalePolicy] clamped to its bounds (0 without the option).
+func (c *commonCond) ScaleTarget() int {
+	return int(c.scaleTarget.Load())
+}
@@ -0,0 +1,71 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestScalePolicy(t *testing.T) {
+	var mu sync.Mutex
+	var calls []int
+	c := New(&sync.Mutex{}, WithScalePolicy(1, 4, func(waiting int) int {
+		mu.Lock()
+		calls = append(calls, waiting)
+		mu.Unlock()
+		return waiting * 2
+	}))
+	defer c.Close()
+	expect := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for c.ScaleTarget() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("want target %d, got %d", want, c.ScaleTarget())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	var wg sync.WaitGroup
+	wait := func(n int) {
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.L.Lock()
+				c.Wait()
+				c.L.Unlock()
+			}()
+		}
+	}
+
+	expect(1) // 0 waiters, clamped to min
+	wait(1)
+	expect(2)
+	wait(2)
+	expect(4) // 6 clamped to max
+	c.Broadcast()
+	wg.Wait()
+	expect(1)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := 1; i < len(calls); i++ {
+		if calls[i] == calls[i-1] {
+			t.Fatalf("scale called twice in a row with %d: %v", calls[i], calls)
+		}
+	}
+}
+
+func TestScalePolicyBounds(t *testing.T) {
+	c := New(&sync.Mutex{}, WithScalePolicy(3, 1, func(int) int { return 0 }))
+	defer c.Close()
+	if c.ScaleTarget() != 3 {
+		t.Fatalf("want 3, got %d", c.ScaleTarget())
+	}
+	if New(&sync.Mutex{}).ScaleTarget() != 0 {
+		t.Fatal("want 0 without the option")
+	}
+}
@@ -0,0 +1,68 @@
+package cond
+
+import (
+	"context"
+	"sync"
+)
+
+// Seq returns the current signal sequence. It is incremented by every Signal, SignalWithContext and broadcast,
+// which is delivered (held signals of paused Cond/RWCond are counted on Resume), even if it wakes nobody.
+func (c *commonCond) Seq() uint64 {
+	return c.seq.Load()
+}
+
+// WaitSeq parks only if the signal sequence still equals seq, i.e. there were no signals since seq was read by [commonCond.Seq].
+// Returns true, if awoken by signal/broadcast or the sequence has already changed, and false, if Cond/RWCond was closed.
+// It does not use locker, so the usual pattern `seq := c.Seq(); if !pred() { c.WaitSeq(seq) }` has no lost wakeups
+// even if pred is read without holding locker: a signal sent after Seq either changes the sequence before WaitSeq checks it
+// or finds the goroutine waiting.
+func (c *commonCond) WaitSeq(seq uint64) bool {
+	return c.waitSeq(nopLocker{}, seq)
+}
+
+// waitSeq is same as WaitSeq, but Unlocks l while parked. If the sequence has already changed, l is never Unlocked.
+func (c *commonCond) waitSeq(l sync.Locker, seq uint64) bool {
+	ok, _ := c.waitSeqContext(l, context.Background(), seq)
+	return ok
+}
+
+// waitSeqContext is same as waitSeq, but returns ctx.Err() if ctx is cancelled before the goroutine is awoken.
+func (c *commonCond) waitSeqContext(l sync.Locker, ctx context.Context, seq uint64) (bool, error) {
+	if c.seq.Load() != seq {
+		return !c.IsClosed(), nil
+	}
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ok, err := c.parkContext(&seqLocker{l: l, c: c, seq: seq, cancel: cancel}, sctx, false)
+	if err != nil {
+		if cerr := ctx.Err(); cerr != nil {
+			return false, cerr
+		}
+		if err == ErrWaitLimit {
+			return false, err
+		}
+		// The sequence changed while parking, report it as a spurious wake.
+		return !c.IsClosed(), nil
+	}
+	return ok, nil
+}
+
+// seqLocker wraps l and re-checks the sequence once wake counted the goroutine and loaded the broadcast channel:
+// signals after the check find the goroutine waiting, and signals before it cancel the wait.
+type seqLocker struct {
+	l      sync.Locker
+	c      *commonCond
+	seq    uint64
+	cancel context.CancelFunc
+}
+
+func (l *seqLocker) Lock() {
+	l.l.Lock()
+}
+
+func (l *seqLocker) Unlock() {
+	l.l.Unlock()
+	if l.c.seq.Load() != l.seq {
+		l.cancel()
+	}
+}
@@ -0,0 +1,74 @@
+package cond_test
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWaitSeq(t *testing.T) {
+	c := New(&sync.Mutex{})
+	seq := c.Seq()
+	c.Signal(1) // wakes nobody, but changes the sequence
+	if c.Seq() == seq {
+		t.Fatal("want sequence to change")
+	}
+	if !c.WaitSeq(seq) {
+		t.Fatal("want true for stale sequence")
+	}
+
+	seq = c.Seq()
+	done := make(chan bool)
+	go func() {
+		done <- c.WaitSeq(seq)
+	}()
+	waitParked(c, 1)
+	c.Broadcast()
+	if !<-done {
+		t.Fatal("want true")
+	}
+
+	seq = c.Seq()
+	c.Close()
+	if c.WaitSeq(seq) {
+		t.Fatal("want false for closed Cond")
+	}
+}
+
+func TestWaitSeqNoLostWakeups(t *testing.T) {
+	c := New(&sync.Mutex{})
+	var state, consumed atomic.Int64
+	n := int64(2000)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// The predicate is read without locker.
+		for i := int64(1); i <= n; i++ {
+			for {
+				seq := c.Seq()
+				if state.Load() >= i {
+					break
+				}
+				c.WaitSeq(seq)
+			}
+			consumed.Store(i)
+		}
+	}()
+	deadline := time.Now().Add(10 * time.Second)
+	for i := int64(1); i <= n; i++ {
+		state.Store(i)
+		c.Signal(1)
+		// Every step races the consumer's Seq/check/WaitSeq against the signal.
+		for consumed.Load() < i {
+			if time.Now().After(deadline) {
+				t.Fatalf("lost wakeup at step %d", i)
+			}
+			runtime.Gosched()
+		}
+	}
+	<-done
+}
@@ -0,0 +1,71 @@
+package cond
+
+import "sync"
+
+// shutdownRegistry holds Conds/RWConds registered by RegisterForShutdown.
+var shutdownRegistry struct {
+	mu    sync.Mutex
+	conds map[CondLike]struct{}
+}
+
+// RegisterForShutdown registers c to be closed by [Shutdown]. The registry holds c until it is closed or unregistered,
+// so Conds/RWConds of this package leave it on Close. Call [UnregisterForShutdown] for other CondLike implementations,
+// which are dropped earlier, to not leak them. Closed Conds/RWConds are not registered. The registry is safe for concurrent use.
+func RegisterForShutdown(c CondLike) {
+	shutdownRegistry.mu.Lock()
+	defer shutdownRegistry.mu.Unlock()
+	var cc *commonCond
+	switch c := c.(type) {
+	case *Cond:
+		cc = &c.commonCond
+	case *RWCond:
+		cc = &c.commonCond
+	}
+	if cc != nil {
+		// Close marks c closed before it unregisters c under the same lock, so c is either skipped here or removed by Close.
+		if cc.closed.Load() {
+			return
+		}
+		cc.shutdownKey = c
+	}
+	if shutdownRegistry.conds == nil {
+		shutdownRegistry.conds = make(map[CondLike]struct{})
+	}
+	shutdownRegistry.conds[c] = struct{}{}
+}
+
+// UnregisterForShutdown removes c registered by [RegisterForShutdown] and reports if it was registered.
+func UnregisterForShutdown(c CondLike) bool {
+	shutdownRegistry.mu.Lock()
+	defer shutdownRegistry.mu.Unlock()
+	_, ok := shutdownRegistry.conds[c]
+	delete(shutdownRegistry.conds, c)
+	return ok
+}
+
+// unregisterForShutdown removes closed c from the registry, so the registry does not retain it.
+func (c *commonCond) unregisterForShutdown() {
+	shutdownRegistry.mu.Lock()
+	defer shutdownRegistry.mu.Unlock()
+	if c.shutdownKey != nil {
+		delete(shutdownRegistry.conds, c.shutdownKey)
+		c.shutdownKey = nil
+	}
+}
+
+// Shutdown closes all registered Conds/RWConds, empties the registry and reports how many of them were closed by this call.
+// Conds/RWConds registered concurrently with Shutdown are either closed by it or stay registered for the next Shutdown.
+func Shutdown() int {
+	shutdownRegistry.mu.Lock()
+	conds := shutdownRegistry.conds
+	shutdownRegistry.conds = nil
+	shutdownRegistry.mu.Unlock()
+
+	closed := 0
+	for c := range conds {
+		if c.Close() {
+			closed++
+		}
+	}
+	return closed
+}
@@ -0,0 +1,52 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestShutdown(t *testing.T) {
+	a, b := New(&sync.Mutex{}), NewRW(&sync.RWMutex{})
+	unregistered, never := New(&sync.Mutex{}), New(&sync.Mutex{})
+	RegisterForShutdown(a)
+	RegisterForShutdown(b)
+	RegisterForShutdown(unregistered)
+	if !UnregisterForShutdown(unregistered) {
+		t.Fatal("want true for registered Cond")
+	}
+	if UnregisterForShutdown(never) {
+		t.Fatal("want false for not registered Cond")
+	}
+
+	parked, result := a.WaitReady()
+	<-parked
+	if n := Shutdown(); n != 2 {
+		t.Fatalf("want 2 closed, got %d", n)
+	}
+	if <-result {
+		t.Fatal("want waiter to be woken by Shutdown")
+	}
+	if !a.IsClosed() || !b.IsClosed() {
+		t.Fatal("want registered conds closed")
+	}
+	if unregistered.IsClosed() || never.IsClosed() {
+		t.Fatal("want unregistered conds untouched")
+	}
+	if n := Shutdown(); n != 0 {
+		t.Fatalf("want empty registry after Shutdown, got %d", n)
+	}
+
+	// Close removes the cond from the registry.
+	closed := New(&sync.Mutex{})
+	RegisterForShutdown(closed)
+	closed.Close()
+	if UnregisterForShutdown(closed) {
+		t.Fatal("want closed Cond unregistered by Close")
+	}
+	RegisterForShutdown(closed)
+	if UnregisterForShutdown(closed) {
+		t.Fatal("want closed Cond not registered")
+	}
+}
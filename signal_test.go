@@ -0,0 +1,586 @@
+package cond_test
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestCondSignalRotation(t *testing.T) {
+	var m sync.Mutex
+	c := New(&m)
+	k := 4
+	signals := 200
+	counts := make([]int, k)
+	for i := 0; i < k; i++ {
+		go func(i int) {
+			m.Lock()
+			for c.Wait() {
+				counts[i]++
+			}
+			m.Unlock()
+		}(i)
+	}
+	for i := 0; i < signals; i++ {
+		for c.WaitCount() < k {
+			runtime.Gosched()
+		}
+		c.Signal(1)
+	}
+	m.Lock()
+	c.Close()
+	m.Unlock()
+	for c.WaitCount() > 0 {
+		runtime.Gosched()
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	want := signals / k
+	for i, got := range counts {
+		if got < want/2 || got > want*2 {
+			t.Fatalf("goroutine %d woke %d times, want about %d (counts %v)", i, got, want, counts)
+		}
+	}
+}
+
+func TestSignalExact(t *testing.T) {
+	var m sync.Mutex
+	c := New(&m)
+	if w, s := c.SignalExact(2); w != 0 || s != 2 {
+		t.Fatalf("no waiters: want 0 and 2, got %d and %d", w, s)
+	}
+
+	n := 3
+	var wg sync.WaitGroup
+	park := func(k int) {
+		for i := 0; i < k; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				m.Lock()
+				c.Wait()
+				m.Unlock()
+			}()
+		}
+		waitParked(c, k)
+	}
+
+	park(n)
+	if w, s := c.SignalExact(0); w != 0 || s != 0 {
+		t.Fatalf("n = 0: want 0 and 0, got %d and %d", w, s)
+	}
+	if c.WaitCount() != n {
+		t.Fatal("SignalExact(0) must not broadcast")
+	}
+	if w, s := c.SignalExact(n); w != n || s != 0 {
+		t.Fatalf("n = waiters: want %d and 0, got %d and %d", n, w, s)
+	}
+	wg.Wait()
+
+	park(n)
+	if w, s := c.SignalExact(n + 2); w != n || s != 2 {
+		t.Fatalf("n > waiters: want %d and 2, got %d and %d", n, w, s)
+	}
+	wg.Wait()
+}
+
+func TestSignalChecked(t *testing.T) {
+	c := New(&sync.Mutex{})
+	go func() {
+		c.L.Lock()
+		c.Wait()
+		c.L.Unlock()
+	}()
+	waitParked(c, 1)
+	if n, err := c.SignalChecked(1); n != 1 || err != nil {
+		t.Fatalf("want 1 and nil, got %d and %v", n, err)
+	}
+
+	c.Close()
+	if n, err := c.SignalChecked(1); n != 0 || err != ErrClosed {
+		t.Fatalf("want 0 and ErrClosed, got %d and %v", n, err)
+	}
+	if n := c.Signal(1); n != 0 {
+		t.Fatalf("Signal on closed Cond: want 0, got %d", n)
+	}
+}
+
+func TestBroadcastWhileLocked(t *testing.T) {
+	var m sync.Mutex
+	c := New(&m)
+	waves, size := 10, 20
+	stage, arrived := -1, 0
+	var wg sync.WaitGroup
+	for w := 0; w < waves; w++ {
+		for i := 0; i < size; i++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				m.Lock()
+				defer m.Unlock()
+				if !c.WaitFor(func() bool { return stage == w }) {
+					t.Error("Cond was closed")
+					return
+				}
+				arrived++
+				// The last goroutine of the wave releases the next wave while holding locker.
+				if arrived == size {
+					arrived = 0
+					stage++
+					c.Broadcast()
+				}
+			}(w)
+		}
+	}
+	waitParked(c, waves*size)
+	m.Lock()
+	stage = 0
+	c.Broadcast()
+	m.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("wavefront got stuck")
+	}
+}
+
+func TestSignalUntilEmpty(t *testing.T) {
+	var m sync.Mutex
+	c := New(&m)
+	n := 10
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Lock()
+			c.Wait()
+			m.Unlock()
+		}()
+	}
+	waitParked(c, n)
+
+	calls := 0
+	if got := c.SignalUntilEmpty(func() { calls++ }); got != n {
+		t.Fatalf("want %d awoken, got %d", n, got)
+	}
+	if calls != n {
+		t.Fatalf("want %d perWake calls, got %d", n, calls)
+	}
+	wg.Wait()
+	if got := c.SignalUntilEmpty(nil); got != 0 {
+		t.Fatalf("no waiters: want 0, got %d", got)
+	}
+}
+
+func TestSignalNoStarvation(t *testing.T) {
+	var m sync.Mutex
+	c := New(&m)
+	k := 6
+	woken := make([]int, k)
+	stop := false
+	var wg sync.WaitGroup
+	for i := 0; i < k; i++ {
+		wg.Add(1)
+		// Half of goroutines re-wait immediately, others are slow.
+		slow := i%2 == 0
+		go func() {
+			defer wg.Done()
+			m.Lock()
+			defer m.Unlock()
+			for !stop {
+				if !c.Wait() {
+					return
+				}
+				woken[i]++
+				if slow {
+					m.Unlock()
+					for j := 0; j < 100; j++ {
+						runtime.Gosched()
+					}
+					m.Lock()
+				}
+			}
+		}()
+	}
+	waitParked(c, k)
+	for i := 0; i < 200; i++ {
+		c.Signal(1)
+	}
+	m.Lock()
+	stop = true
+	m.Unlock()
+	c.Close()
+	wg.Wait()
+
+	// Parked goroutines are served in order, so fast re-waiters can not keep slow ones from being woken at all.
+	// How many signals a goroutine waits for depends on how long it takes to reach the queue, so it is not bounded.
+	for i, n := range woken {
+		if n == 0 {
+			t.Fatalf("goroutine %d was never woken", i)
+		}
+	}
+}
+
+func TestSignalMaxInt(t *testing.T) {
+	var m sync.Mutex
+	c := New(&m)
+	if n := c.Signal(math.MaxInt); n != 0 {
+		t.Fatalf("no waiters: want 0, got %d", n)
+	}
+
+	k := 5
+	var wg sync.WaitGroup
+	for i := 0; i < k; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Lock()
+			c.Wait()
+			m.Unlock()
+		}()
+	}
+	waitParked(c, k)
+	// A goroutine, which is counted, but not parked yet, may be missed by one call, but never counted twice.
+	woken := 0
+	for woken < k {
+		woken += c.Signal(math.MaxInt)
+	}
+	if woken != k {
+		t.Fatalf("want %d awoken, got %d", k, woken)
+	}
+	wg.Wait()
+	if w, s := c.SignalExact(math.MaxInt); w != 0 || s != math.MaxInt {
+		t.Fatalf("want 0 and MaxInt, got %d and %d", w, s)
+	}
+
+	c.Pause()
+	c.Signal(math.MaxInt)
+	c.Signal(math.MaxInt)
+	c.Resume()
+}
+
+func TestSignalBroadcastPathCount(t *testing.T) {
+	var observed atomic.Int64
+	c := New(&sync.Mutex{}, WithBroadcastObserver(func(woken int) {
+		observed.Store(int64(woken))
+	}))
+	park := func(n int) []<-chan bool {
+		results := make([]<-chan bool, n)
+		for i := range results {
+			parked, result := c.WaitReady()
+			<-parked
+			results[i] = result
+		}
+		return results
+	}
+	drain := func(results []<-chan bool) {
+		for _, r := range results {
+			<-r
+		}
+	}
+
+	results := park(3)
+	if m := c.Signal(0); m != 3 {
+		t.Fatalf("Signal(0): want 3, got %d", m)
+	}
+	drain(results)
+
+	results = park(3)
+	c.Broadcast()
+	if m := observed.Load(); m != 3 {
+		t.Fatalf("Broadcast: want 3, got %d", m)
+	}
+	drain(results)
+
+	results = park(2)
+	if m, err := c.SignalWithContext(context.Background(), -1); m != 2 || err != nil {
+		t.Fatalf("SignalWithContext(ctx, -1): want 2 and nil, got %d and %v", m, err)
+	}
+	drain(results)
+
+	if m := c.Signal(0); m != 0 {
+		t.Fatalf("no waiters: want 0, got %d", m)
+	}
+}
+
+// slowParkLocker delays parking: the goroutine is counted as waiting, but does not receive signals until Unlock returns.
+type slowParkLocker struct {
+	sync.Mutex
+	delay time.Duration
+}
+
+func (l *slowParkLocker) Unlock() {
+	l.Mutex.Unlock()
+	time.Sleep(l.delay)
+}
+
+func TestSignalSpinIterations(t *testing.T) {
+	l := &slowParkLocker{}
+	c := New(l)
+	if c.SignalSpinIterations() != 0 {
+		t.Fatal("want 0 initially")
+	}
+	parked, result := c.WaitReady()
+	<-parked
+	c.Signal(1)
+	<-result
+	if n := c.SignalSpinIterations(); n != 1 {
+		t.Fatalf("parked waiter: want 1 iteration, got %d", n)
+	}
+
+	l.delay = 20 * time.Millisecond
+	done := make(chan bool)
+	go func() {
+		l.Lock()
+		done <- c.Wait()
+		l.Mutex.Unlock()
+	}()
+	waitParked(c, 1)
+	// The waiter is counted, but sleeps in Unlock, so Signal spins until it parks.
+	if c.Signal(1) != 1 {
+		t.Fatal("want 1")
+	}
+	<-done
+	if n := c.SignalSpinIterations(); n <= 2 {
+		t.Fatalf("want the counter to grow under contention, got %d", n)
+	}
+}
+
+// gateLocker blocks Unlock until gate is closed: the goroutine is counted as waiting, but does not receive signals yet.
+type gateLocker struct {
+	sync.Mutex
+	gate chan struct{}
+}
+
+func (l *gateLocker) Unlock() {
+	l.Mutex.Unlock()
+	<-l.gate
+}
+
+func TestSignalWithContextCreditsCancelledWaiter(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		l := &gateLocker{gate: make(chan struct{})}
+		c := New(l)
+		ctx, cancel := context.WithCancel(context.Background())
+		left := make(chan error, 1)
+		go func() {
+			l.Lock()
+			_, err := c.WaitWithContext(ctx)
+			l.Mutex.Unlock()
+			left <- err
+		}()
+		waitParked(c, 1)
+
+		type result struct {
+			n   int
+			err error
+		}
+		signalled := make(chan result, 1)
+		go func() {
+			n, err := c.SignalWithContext(context.Background(), 1)
+			signalled <- result{n, err}
+		}()
+		// Let the signal get in flight. Then the waiter is cancelled: it either takes the signal or leaves.
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		close(l.gate)
+		select {
+		case r := <-signalled:
+			err := <-left
+			if r.err != nil || (r.n == 1) != (err == nil) {
+				t.Fatalf("want signal to be taken or credited, got %+v and waiter error %v", r, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("SignalWithContext blocked for a waiter, which left")
+		}
+	}
+}
+
+func TestSignalWithContextDoesNotCreditPollTimeouts(t *testing.T) {
+	// Poll intervals end parks with internal contexts, but the goroutine parks again, so it must not be credited as gone.
+	for i := 0; i < 20; i++ {
+		l := &gateLocker{gate: make(chan struct{})}
+		c := New(l)
+		ready := false
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			l.Lock()
+			c.WaitForPoll(func() bool { return ready }, 5*time.Millisecond, 5*time.Millisecond)
+			l.Mutex.Unlock()
+		}()
+		waitParked(c, 1)
+
+		signalled := make(chan int, 1)
+		go func() {
+			n, _ := c.SignalWithContext(context.Background(), 1)
+			signalled <- n
+		}()
+		// The waiter is held in Unlock until its poll interval expires, so it observes the signal and the timeout at once.
+		time.Sleep(10 * time.Millisecond)
+		close(l.gate)
+		if n := <-signalled; n != 1 {
+			t.Fatalf("want the polling waiter to take the signal, got %d", n)
+		}
+		l.Mutex.Lock()
+		ready = true
+		l.Mutex.Unlock()
+		c.Broadcast()
+		<-done
+	}
+}
+
+func TestSignalBroadcastRaceAccounting(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		c := New(&sync.Mutex{})
+		waiters := 8
+		var returned atomic.Int64
+		var wg sync.WaitGroup
+		for i := 0; i < waiters; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.L.Lock()
+				c.Wait()
+				c.L.Unlock()
+				returned.Add(1)
+			}()
+		}
+		waitParked(c, waiters)
+
+		var signalled atomic.Int64
+		stop := make(chan struct{})
+		var signallers sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			signallers.Add(2)
+			go func() {
+				defer signallers.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						signalled.Add(int64(c.Signal(1)))
+					}
+				}
+			}()
+			go func() {
+				defer signallers.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						c.Broadcast()
+						runtime.Gosched()
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(stop)
+		signallers.Wait()
+
+		// Every waiter returned once and a signal handoff is never shared with a broadcast.
+		if n := returned.Load(); n != int64(waiters) {
+			t.Fatalf("want %d returns, got %d", waiters, n)
+		}
+		if n := signalled.Load(); n > int64(waiters) {
+			t.Fatalf("Signal reported %d wakes for %d waiters", n, waiters)
+		}
+		if st := c.Stats(); st.Signalled != uint64(signalled.Load()) {
+			t.Fatalf("want Stats.Signalled %d, got %d", signalled.Load(), st.Signalled)
+		}
+	}
+}
+
+func TestMissedSignals(t *testing.T) {
+	c := New(&sync.Mutex{})
+	c.Signal(1)
+	c.Signal(3)
+	c.Broadcast()
+	if n := c.MissedSignals(); n != 3 {
+		t.Fatalf("want 3 missed, got %d", n)
+	}
+
+	parked, result := c.WaitReady()
+	<-parked
+	c.Signal(1)
+	<-result
+	parked, result = c.WaitReady()
+	<-parked
+	c.Broadcast()
+	<-result
+	if n := c.MissedSignals(); n != 3 {
+		t.Fatalf("signals with waiters must not be missed, got %d", n)
+	}
+
+	c.Pause()
+	c.Signal(1)
+	c.Signal(1)
+	c.Resume()
+	if n := c.MissedSignals(); n != 4 {
+		t.Fatalf("want held signals counted once on Resume, got %d", n)
+	}
+	c.Close()
+	c.Signal(1)
+	c.Broadcast()
+	if n := c.MissedSignals(); n != 4 {
+		t.Fatalf("want signals on closed Cond not counted, got %d", n)
+	}
+
+	// Retained signals are not missed, but signals dropped by a full buffer are.
+	c = New(&sync.Mutex{}, WithSignalBuffer(1))
+	c.Signal(1)
+	if n := c.MissedSignals(); n != 0 {
+		t.Fatalf("want retained signal not missed, got %d", n)
+	}
+	c.Signal(1)
+	if n := c.MissedSignals(); n != 1 {
+		t.Fatalf("want signal dropped by full buffer missed, got %d", n)
+	}
+}
+
+func TestWakeOrderTracking(t *testing.T) {
+	var m sync.Mutex
+	c := New(&m, WithWakeOrderTracking())
+	k := 5
+	done := make(chan struct{})
+	for i := 0; i < k; i++ {
+		go func() {
+			m.Lock()
+			c.Wait()
+			m.Unlock()
+			done <- struct{}{}
+		}()
+		waitParked(c, i+1)
+		// Let the goroutine block on the channel before the next one is counted.
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < k; i++ {
+		c.Signal(1)
+		<-done
+	}
+	want := []WaiterID{1, 2, 3, 4, 5}
+	if got := c.WakeOrder(); !slices.Equal(got, want) {
+		t.Fatalf("want FIFO wake order %v, got %v", want, got)
+	}
+	if got := New(&m).WakeOrder(); got != nil {
+		t.Fatalf("want nil without tracking, got %v", got)
+	}
+}
@@ -0,0 +1,19 @@
+package cond
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+)
+
+// spurious reports if the wait should end spuriously (see [WithSpuriousWakeups]).
+func (c *commonCond) spurious() bool {
+	return c.opts.spurious > 0 && !c.closed.Load() && rand.Float64() < c.opts.spurious
+}
+
+// wakeSpuriously releases l, lets other goroutines run and Locks l again, as a wait, which was awoken without a signal.
+func wakeSpuriously(l sync.Locker) {
+	l.Unlock()
+	runtime.Gosched()
+	l.Lock()
+}
@@ -0,0 +1,23 @@
+package cond
+
+// Stats is a snapshot of Cond/RWCond counters. Fields are read independently, so they may be inconsistent with each other.
+type Stats struct {
+	// Waiting is the number of goroutines waiting for signal.
+	Waiting int
+	// Closed reports if Cond/RWCond is closed.
+	Closed bool
+	// Signalled is the total number of goroutines awoken by Signal and SignalWithContext.
+	Signalled uint64
+	// Broadcasts is the total number of broadcasts (including heartbeats).
+	Broadcasts uint64
+}
+
+// Stats returns a snapshot of Cond/RWCond counters. It only reads atomics.
+func (c *commonCond) Stats() Stats {
+	return Stats{
+		Waiting:    c.s.WaitCount(),
+		Closed:     c.closed.Load(),
+		Signalled:  c.signalled.Load(),
+		Broadcasts: c.broadcasts.Load(),
+	}
+}
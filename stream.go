@@ -0,0 +1,74 @@
+package cond
+
+import (
+	"context"
+	"sync"
+)
+
+// ValueStream holds the latest value and lets watchers wait until it changes ("watch latest config" pattern).
+// Intermediate values, which were replaced before a watcher read them, are skipped, so slow watchers never queue up changes.
+// All methods are thread safe.
+type ValueStream[T any] struct {
+	mu      sync.Mutex
+	c       *Cond
+	value   T
+	version uint64
+}
+
+// ValueWatcher reads changes of [ValueStream]. A ValueWatcher must not be used by several goroutines concurrently.
+type ValueWatcher[T any] struct {
+	s       *ValueStream[T]
+	version uint64
+}
+
+// NewValueStream returns ValueStream without a value. Watchers block until the first Set.
+func NewValueStream[T any]() *ValueStream[T] {
+	s := &ValueStream[T]{}
+	s.c = New(&s.mu)
+	return s
+}
+
+// Set replaces the value and wakes all watchers. Returns false and drops v, if ValueStream was closed.
+func (s *ValueStream[T]) Set(v T) bool {
+	set := false
+	s.c.BroadcastAndSet(func() {
+		if s.c.IsClosed() {
+			return
+		}
+		s.value = v
+		s.version++
+		set = true
+	})
+	return set
+}
+
+// Watch returns ValueWatcher, which has not read any value yet, so its first Next returns the current value, if it was Set.
+func (s *ValueStream[T]) Watch() *ValueWatcher[T] {
+	return &ValueWatcher[T]{s: s}
+}
+
+// Close closes ValueStream and wakes all watchers. The first Close returns true.
+func (s *ValueStream[T]) Close() bool {
+	return s.c.Close()
+}
+
+// Next blocks until the value changed since the last Next of w and returns the latest value and true.
+// A change, which was Set before Close, is still returned. Afterwards Next returns the latest value and false, if ValueStream was closed,
+// and zero value, false and ctx.Err(), if ctx was cancelled.
+func (w *ValueWatcher[T]) Next(ctx context.Context) (T, bool, error) {
+	s := w.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.version == w.version {
+		ok, err := s.c.WaitWithContext(ctx)
+		if err != nil {
+			var zero T
+			return zero, false, err
+		}
+		if !ok {
+			return s.value, false, nil
+		}
+	}
+	w.version = s.version
+	return s.value, true, nil
+}
@@ -0,0 +1,75 @@
+package cond_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestValueStream(t *testing.T) {
+	s := NewValueStream[string]()
+	w := s.Watch()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, ok, err := w.Next(ctx); ok || err != context.DeadlineExceeded {
+		t.Fatalf("want to block without value, got %v and %v", ok, err)
+	}
+
+	s.Set("a")
+	s.Set("b")
+	if v, ok, err := w.Next(context.Background()); v != "b" || !ok || err != nil {
+		t.Fatalf("want latest value b, got %q, %v and %v", v, ok, err)
+	}
+	// A new watcher reads the current value at once.
+	if v, ok, _ := s.Watch().Next(context.Background()); v != "b" || !ok {
+		t.Fatalf("want b for a new watcher, got %q and %v", v, ok)
+	}
+
+	s.Set("c")
+	s.Close()
+	if s.Set("d") {
+		t.Fatal("want Set on closed ValueStream to return false")
+	}
+	if v, ok, _ := w.Next(context.Background()); v != "c" || !ok {
+		t.Fatalf("want change before close, got %q and %v", v, ok)
+	}
+	if v, ok, err := w.Next(context.Background()); v != "c" || ok || err != nil {
+		t.Fatalf("want c, false and nil after close, got %q, %v and %v", v, ok, err)
+	}
+}
+
+func TestValueStreamCoalescing(t *testing.T) {
+	s := NewValueStream[int]()
+	n := 1000
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= n; i++ {
+			s.Set(i)
+		}
+	}()
+
+	w := s.Watch()
+	last, reads := 0, 0
+	for last != n {
+		v, ok, err := w.Next(context.Background())
+		if !ok || err != nil {
+			t.Fatalf("want true and nil, got %v and %v", ok, err)
+		}
+		if v <= last {
+			t.Fatalf("want increasing values, got %d after %d", v, last)
+		}
+		last = v
+		reads++
+		// A slow consumer.
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+	if reads >= n {
+		t.Fatalf("want intermediate values to be skipped, got %d reads", reads)
+	}
+}
@@ -0,0 +1,133 @@
+//go:build go1.25
+
+package cond_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+// Waits park on channels, so a Cond created inside a synctest bubble is durably blocking and fake time advances.
+func TestSynctest(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := New(&sync.Mutex{})
+		defer c.Close()
+
+		start := time.Now()
+		c.L.Lock()
+		ok, err := c.WaitForTimeout(func() bool { return false }, time.Hour)
+		c.L.Unlock()
+		if ok || err != context.DeadlineExceeded {
+			t.Fatalf("want false and context.DeadlineExceeded, got %v and %v", ok, err)
+		}
+		if elapsed := time.Since(start); elapsed != time.Hour {
+			t.Fatalf("want exactly 1h of fake time, got %v", elapsed)
+		}
+
+		woken := make(chan bool, 1)
+		go func() {
+			c.L.Lock()
+			woken <- c.Wait()
+			c.L.Unlock()
+		}()
+		synctest.Wait()
+		if c.WaitCount() != 1 {
+			t.Fatal("want the goroutine to be durably blocked in Wait")
+		}
+		c.Signal(1)
+		if !<-woken {
+			t.Fatal("want true")
+		}
+	})
+}
+
+func TestSynctestHeartbeat(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := New(&sync.Mutex{}, WithHeartbeat(time.Minute))
+		defer c.Close()
+
+		start := time.Now()
+		c.L.Lock()
+		c.Wait()
+		c.L.Unlock()
+		if elapsed := time.Since(start); elapsed != time.Minute {
+			t.Fatalf("want heartbeat after 1m of fake time, got %v", elapsed)
+		}
+
+		// Heartbeats of an idle Cond are not missed signals.
+		idle := New(&sync.Mutex{}, WithHeartbeat(time.Second), WithSignalRate())
+		defer idle.Close()
+		time.Sleep(10 * time.Second)
+		synctest.Wait()
+		if idle.Seq() == 0 {
+			t.Fatal("want heartbeats to advance Seq")
+		}
+		if n, r := idle.MissedSignals(), idle.SignalRate(10*time.Second); n != 0 || r != 0 {
+			t.Fatalf("want heartbeats not counted, got %d missed and rate %v", n, r)
+		}
+	})
+}
+
+func TestSynctestWaitForTimeoutBoundary(t *testing.T) {
+	const d = 50 * time.Millisecond
+	for _, tc := range []struct {
+		name  string
+		ready time.Duration
+		want  bool
+	}{
+		{"before", d - 5*time.Millisecond, true},
+		{"after", d + 5*time.Millisecond, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				c := New(&sync.Mutex{})
+				defer c.Close()
+				x := 0
+				stop := make(chan struct{})
+				defer close(stop)
+				go func() {
+					// Flapping wakes re-park the waiter with the remaining time until the end.
+					ticker := time.NewTicker(time.Millisecond)
+					defer ticker.Stop()
+					for {
+						select {
+						case <-stop:
+							return
+						case <-ticker.C:
+							c.Broadcast()
+						}
+					}
+				}()
+				set := make(chan struct{})
+				go func() {
+					defer close(set)
+					time.Sleep(tc.ready)
+					c.L.Lock()
+					x = 1
+					c.L.Unlock()
+				}()
+
+				start := time.Now()
+				c.L.Lock()
+				ok, err := c.WaitForTimeout(func() bool { return x == 1 }, d)
+				c.L.Unlock()
+				elapsed := time.Since(start)
+				<-set
+				if tc.want {
+					if !ok || err != nil || elapsed > tc.ready+time.Millisecond {
+						t.Fatalf("want true and nil by the next wake, got %v and %v after %v", ok, err, elapsed)
+					}
+					return
+				}
+				if ok || err != context.DeadlineExceeded || elapsed != d {
+					t.Fatalf("want false and context.DeadlineExceeded after %v, got %v and %v after %v", d, ok, err, elapsed)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package cond
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// timedLocker measures how long the goroutine was parked: from releasing locker to being awoken (before re-locking).
+type timedLocker struct {
+	l     sync.Locker
+	start time.Time
+	end   time.Time
+}
+
+func (l *timedLocker) Lock() {
+	l.end = time.Now()
+	l.l.Lock()
+}
+
+func (l *timedLocker) Unlock() {
+	l.l.Unlock()
+	l.start = time.Now()
+}
+
+// parked returns park duration or 0, if the goroutine was not parked. Durations use the monotonic clock,
+// so they are not affected by wall clock changes, but include scheduling delay of waking the goroutine.
+func (l *timedLocker) parked() time.Duration {
+	if l.start.IsZero() {
+		return 0
+	}
+	return l.end.Sub(l.start)
+}
+
+func (c *commonCond) waitTimed(l sync.Locker) (bool, time.Duration) {
+	tl := &timedLocker{l: l}
+	ok := c.park(tl)
+	return ok, tl.parked()
+}
+
+func (c *commonCond) waitTimedWithContext(l sync.Locker, ctx context.Context) (bool, time.Duration, error) {
+	tl := &timedLocker{l: l}
+	ok, err := c.closeError(c.parkContext(tl, ctx, true))
+	return ok, tl.parked(), err
+}
+
+// WaitTimed is same as [Cond.Wait], but also reports how long the goroutine was parked (0 if it was not parked).
+// Duration is measured with the monotonic clock from releasing locker to being awoken, excluding re-locking.
+func (c *Cond) WaitTimed() (bool, time.Duration) {
+	return c.waitTimed(c.L)
+}
+
+// WaitTimedWithContext is same as [Cond.WaitWithContext], but also reports how long the goroutine was parked (see [Cond.WaitTimed]).
+func (c *Cond) WaitTimedWithContext(ctx context.Context) (bool, time.Duration, error) {
+	return c.waitTimedWithContext(c.L, ctx)
+}
+
+// WaitTimed is same as [RWCond.Wait], but also reports how long the goroutine was parked (0 if it was not parked).
+// Duration is measured with the monotonic clock from releasing locker to being awoken, excluding re-locking.
+func (c *RWCond) WaitTimed() (bool, time.Duration) {
+	return c.waitTimed(c.rwl)
+}
+
+// WaitTimedWithContext is same as [RWCond.WaitWithContext], but also reports how long the goroutine was parked (see [RWCond.WaitTimed]).
+func (c *RWCond) WaitTimedWithContext(ctx context.Context) (bool, time.Duration, error) {
+	return c.waitTimedWithContext(c.rwl, ctx)
+}
@@ -0,0 +1,53 @@
+package cond_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWaitTimed(t *testing.T) {
+	c := New(&sync.Mutex{})
+	d := 20 * time.Millisecond
+	go func() {
+		waitParked(c, 1)
+		time.Sleep(d)
+		c.Signal(1)
+	}()
+	c.L.Lock()
+	ok, parked := c.WaitTimed()
+	c.L.Unlock()
+	if !ok {
+		t.Fatal("want true")
+	}
+	if parked < d/2 || parked > time.Second {
+		t.Fatalf("want parked about %v, got %v", d, parked)
+	}
+
+	c.Close()
+	c.L.Lock()
+	ok, parked = c.WaitTimed()
+	c.L.Unlock()
+	if ok || parked != 0 {
+		t.Fatalf("closed: want false and 0, got %v and %v", ok, parked)
+	}
+}
+
+func TestWaitTimedWithContext(t *testing.T) {
+	c := New(&sync.Mutex{})
+	d := 20 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	c.L.Lock()
+	ok, parked, err := c.WaitTimedWithContext(ctx)
+	c.L.Unlock()
+	if ok || err != context.DeadlineExceeded {
+		t.Fatalf("want false and context.DeadlineExceeded, got %v and %v", ok, err)
+	}
+	if parked < d/2 || parked > time.Second {
+		t.Fatalf("want parked about %v, got %v", d, parked)
+	}
+}
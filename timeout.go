@@ -0,0 +1,26 @@
+package cond
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SetDefaultWaitTimeout makes plain Wait a timed wait, which returns false, if it is not awoken within d, so code,
+// which only calls Wait, can not hang indefinitely. d <= 0 disables the timeout. Other Wait methods are not affected:
+// methods with context use only the explicit context, so it overrides the default timeout.
+// Use IsClosed to tell a timeout from Close. It applies to waits, which start after the call.
+func (c *commonCond) SetDefaultWaitTimeout(d time.Duration) {
+	c.timeout.Store(int64(d))
+}
+
+func (c *commonCond) wait(l sync.Locker) bool {
+	d := time.Duration(c.timeout.Load())
+	if d <= 0 {
+		return c.park(l)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	ok, _ := c.parkContext(l, ctx, false)
+	return ok
+}
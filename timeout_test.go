@@ -0,0 +1,58 @@
+package cond_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestDefaultWaitTimeout(t *testing.T) {
+	c := New(&sync.Mutex{}, WithDefaultTimeout(10*time.Millisecond))
+	c.L.Lock()
+	start := time.Now()
+	if c.Wait() {
+		t.Fatal("want false on timeout")
+	}
+	if time.Since(start) < 10*time.Millisecond {
+		t.Fatal("returned before the timeout")
+	}
+	if c.IsClosed() {
+		t.Fatal("timeout must not close Cond")
+	}
+
+	// Explicit context overrides the default timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if _, err := c.WaitWithContext(ctx); err != context.DeadlineExceeded || time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("want explicit deadline to be used, got %v after %v", err, time.Since(start))
+	}
+	c.L.Unlock()
+
+	c.SetDefaultWaitTimeout(0)
+	parked := make(chan bool)
+	go func() {
+		c.L.Lock()
+		parked <- c.Wait()
+		c.L.Unlock()
+	}()
+	waitParked(c, 1)
+	time.Sleep(20 * time.Millisecond)
+	c.Signal(1)
+	if !<-parked {
+		t.Fatal("want true without timeout")
+	}
+}
+
+func TestRWCondDefaultWaitTimeout(t *testing.T) {
+	c := NewRW(&sync.RWMutex{})
+	c.SetDefaultWaitTimeout(time.Millisecond)
+	c.L.RLock()
+	defer c.L.RUnlock()
+	if c.Wait() {
+		t.Fatal("want false on timeout")
+	}
+}
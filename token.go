@@ -0,0 +1,66 @@
+package cond
+
+import "sync"
+
+// WaitToken is returned by BeginWait and must be passed to a single following EndWait.
+type WaitToken struct {
+	c   *commonCond
+	id  uint64
+	seq uint64
+}
+
+// tokens tracks WaitTokens, which were begun, but not ended yet, if [WithWaitTokens] is set.
+type tokens struct {
+	mu   sync.Mutex
+	next uint64
+	open map[uint64]struct{}
+}
+
+// BeginWait starts a wait and returns WaitToken for EndWait. Call it with locker held before evaluating shared state:
+// EndWait does not park, if there were signals since BeginWait, so a signal sent between evaluating state and EndWait is never lost
+// and code can not wait twice for the same state change.
+func (c *commonCond) BeginWait() WaitToken {
+	t := WaitToken{c: c, seq: c.seq.Load()}
+	if c.opts.waitTokens {
+		c.tokens.mu.Lock()
+		c.tokens.next++
+		t.id = c.tokens.next
+		if c.tokens.open == nil {
+			c.tokens.open = make(map[uint64]struct{})
+		}
+		c.tokens.open[t.id] = struct{}{}
+		c.tokens.mu.Unlock()
+	}
+	return t
+}
+
+// end checks and retires t, if [WithWaitTokens] is set.
+func (c *commonCond) end(t WaitToken) {
+	if !c.opts.waitTokens {
+		return
+	}
+	if t.c != c {
+		panic("cond: EndWait with WaitToken of another Cond")
+	}
+	c.tokens.mu.Lock()
+	_, ok := c.tokens.open[t.id]
+	delete(c.tokens.open, t.id)
+	c.tokens.mu.Unlock()
+	if !ok {
+		panic("cond: EndWait with reused WaitToken")
+	}
+}
+
+// EndWait finishes the wait started by [commonCond.BeginWait]: if there were no signals since BeginWait, it Unlocks locker,
+// blocks until awaken and Locks locker again. Otherwise it returns immediately without Unlocking locker.
+// Returns false, if Cond was closed. With [WithWaitTokens] it panics, if t is reused or was returned by another Cond/RWCond.
+func (c *Cond) EndWait(t WaitToken) bool {
+	c.end(t)
+	return c.waitSeq(c.L, t.seq)
+}
+
+// EndWait is same as [Cond.EndWait], but RUnlocks and RLocks locker.
+func (c *RWCond) EndWait(t WaitToken) bool {
+	c.end(t)
+	return c.waitSeq(c.rwl, t.seq)
+}
@@ -0,0 +1,82 @@
+package cond_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/nursik/go-cond"
+)
+
+func mustPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s: want panic", name)
+		}
+	}()
+	f()
+}
+
+func TestBeginEndWait(t *testing.T) {
+	c := New(&sync.Mutex{})
+	x := 0
+	done := make(chan bool)
+	go func() {
+		c.L.Lock()
+		defer c.L.Unlock()
+		tok := c.BeginWait()
+		for x == 0 {
+			if !c.EndWait(tok) {
+				done <- false
+				return
+			}
+			tok = c.BeginWait()
+		}
+		done <- true
+	}()
+	waitParked(c, 1)
+	c.L.Lock()
+	x = 1
+	c.L.Unlock()
+	c.Signal(1)
+	if !<-done {
+		t.Fatal("want true")
+	}
+
+	// A signal between BeginWait and EndWait is not lost.
+	c.L.Lock()
+	tok := c.BeginWait()
+	c.Signal(1)
+	ok := c.EndWait(tok)
+	c.L.Unlock()
+	if !ok {
+		t.Fatal("want true without parking")
+	}
+}
+
+func TestWaitTokens(t *testing.T) {
+	c := New(&sync.Mutex{}, WithWaitTokens())
+	other := New(&sync.Mutex{}, WithWaitTokens())
+	c.L.Lock()
+	defer c.L.Unlock()
+
+	tok := c.BeginWait()
+	c.Signal(1)
+	if !c.EndWait(tok) {
+		t.Fatal("want true")
+	}
+	mustPanic(t, "double EndWait", func() { c.EndWait(tok) })
+
+	tok = other.BeginWait()
+	mustPanic(t, "foreign token", func() { c.EndWait(tok) })
+	mustPanic(t, "zero token", func() { c.EndWait(WaitToken{}) })
+
+	// Without the option tokens are not checked.
+	lean := New(&sync.Mutex{})
+	tok = lean.BeginWait()
+	lean.Signal(1)
+	lean.L.Lock()
+	lean.EndWait(tok)
+	lean.EndWait(tok)
+	lean.L.Unlock()
+}
@@ -0,0 +1,388 @@
+package cond_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWaitWithContextEx(t *testing.T) {
+	type result struct {
+		woken, parked bool
+		err           error
+	}
+	wait := func(c *Cond, ctx context.Context) <-chan result {
+		ch := make(chan result, 1)
+		go func() {
+			c.L.Lock()
+			woken, parked, err := c.WaitWithContextEx(ctx)
+			c.L.Unlock()
+			ch <- result{woken, parked, err}
+		}()
+		return ch
+	}
+	check := func(name string, got, want result) {
+		t.Helper()
+		if got != want {
+			t.Errorf("%s: want %+v, got %+v", name, want, got)
+		}
+	}
+
+	c := New(&sync.Mutex{})
+	ch := wait(c, context.Background())
+	waitParked(c, 1)
+	c.Signal(1)
+	check("signalled", <-ch, result{true, true, nil})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch = wait(c, ctx)
+	waitParked(c, 1)
+	cancel()
+	check("cancelled while parked", <-ch, result{false, true, context.Canceled})
+
+	check("already cancelled", <-wait(c, ctx), result{false, false, context.Canceled})
+
+	ch = wait(c, context.Background())
+	waitParked(c, 1)
+	c.Close()
+	check("closed while parked", <-ch, result{false, true, nil})
+
+	check("already closed", <-wait(c, context.Background()), result{false, false, nil})
+}
+
+func TestWaitWithContexts(t *testing.T) {
+	c := New(&sync.Mutex{})
+	alive := context.Background()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+
+	c.L.Lock()
+	ok, err := c.WaitWithContexts(alive, cancelled, expired)
+	if ok || err != context.Canceled {
+		t.Fatalf("want false and context.Canceled, got %v and %v", ok, err)
+	}
+	ok, err = c.WaitWithContexts(alive, expired, cancelled)
+	if ok || err != context.DeadlineExceeded {
+		t.Fatalf("want false and context.DeadlineExceeded, got %v and %v", ok, err)
+	}
+	c.L.Unlock()
+
+	type result struct {
+		ok  bool
+		err error
+	}
+	wait := func(ctxs ...context.Context) <-chan result {
+		ch := make(chan result, 1)
+		go func() {
+			c.L.Lock()
+			ok, err := c.WaitWithContexts(ctxs...)
+			c.L.Unlock()
+			ch <- result{ok, err}
+		}()
+		waitParked(c, 1)
+		return ch
+	}
+
+	request, cancelRequest := context.WithCancel(context.Background())
+	shutdown, cancelShutdown := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelShutdown()
+	ch := wait(request, shutdown)
+	cancelRequest()
+	if r := <-ch; r.ok || r.err != context.Canceled {
+		t.Fatalf("want false and context.Canceled, got %v and %v", r.ok, r.err)
+	}
+
+	ch = wait(shutdown, alive)
+	c.Signal(1)
+	if r := <-ch; !r.ok || r.err != nil {
+		t.Fatalf("want true and nil, got %v and %v", r.ok, r.err)
+	}
+}
+
+// panicLocker panics on Lock after it was unlocked once.
+type panicLocker struct {
+	sync.Mutex
+	unlocked bool
+}
+
+func (l *panicLocker) Unlock() {
+	l.unlocked = true
+	l.Mutex.Unlock()
+}
+
+func (l *panicLocker) Lock() {
+	if l.unlocked {
+		panic("lock failed")
+	}
+	l.Mutex.Lock()
+}
+
+func TestWaitLockPanic(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithWaitCountEWMA(0.5)}} {
+		l := &panicLocker{}
+		c := New(l, opts...)
+		done := make(chan any)
+		go func() {
+			defer func() {
+				done <- recover()
+			}()
+			l.Lock()
+			c.Wait()
+		}()
+		waitParked(c, 1)
+		c.Signal(1)
+		if r := <-done; r == nil {
+			t.Fatal("want Lock to panic")
+		}
+		if n := c.WaitCount(); n != 0 {
+			t.Fatalf("want 0 waiters after panic, got %d", n)
+		}
+	}
+}
+
+func TestWaitWithContextCause(t *testing.T) {
+	c := New(&sync.Mutex{})
+	cause := errors.New("shutting down")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		c.L.Lock()
+		_, err := c.WaitWithContextCause(ctx)
+		c.L.Unlock()
+		done <- err
+	}()
+	waitParked(c, 1)
+	cancel(cause)
+	if err := <-done; err != cause {
+		t.Fatalf("want cause %v, got %v", cause, err)
+	}
+
+	ctx, cancelPlain := context.WithCancel(context.Background())
+	cancelPlain()
+	c.L.Lock()
+	_, err := c.WaitWithContextCause(ctx)
+	c.L.Unlock()
+	if err != context.Canceled {
+		t.Fatalf("without cause: want context.Canceled, got %v", err)
+	}
+}
+
+// goroutinesCreated returns the number of goroutines created by the program, if the runtime reports it (Go 1.26+).
+func goroutinesCreated() (uint64, bool) {
+	s := []metrics.Sample{{Name: "/sched/goroutines-created:goroutines"}}
+	metrics.Read(s)
+	if s[0].Value.Kind() != metrics.KindUint64 {
+		return 0, false
+	}
+	return s[0].Value.Uint64(), true
+}
+
+// cancelOnUnlock cancels its context right after Unlock, i.e. when the waiter is parking, without timers or goroutines.
+type cancelOnUnlock struct {
+	sync.Mutex
+	context.Context
+	done chan struct{}
+}
+
+func (l *cancelOnUnlock) Unlock() {
+	l.Mutex.Unlock()
+	close(l.done)
+}
+
+func (l *cancelOnUnlock) Done() <-chan struct{} {
+	return l.done
+}
+
+func (l *cancelOnUnlock) Err() error {
+	select {
+	case <-l.done:
+		return context.Canceled
+	default:
+		return nil
+	}
+}
+
+// BenchmarkWaitWithContextChurn runs short cancellable waits, which are cancelled before parking or while parking.
+// It reports goroutines created per wait (or the peak number of extra goroutines on runtimes without the metric),
+// which stays 0: wake selects on ctx.Done() directly, so cancellable waits never spawn watcher goroutines.
+func BenchmarkWaitWithContextChurn(b *testing.B) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	bench := func(b *testing.B, wait func() error) {
+		base := runtime.NumGoroutine()
+		peak := base
+		created, ok := goroutinesCreated()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := wait(); err == nil {
+				b.Fatal("want context error")
+			}
+			peak = max(peak, runtime.NumGoroutine())
+		}
+		b.StopTimer()
+		if now, _ := goroutinesCreated(); ok {
+			b.ReportMetric(float64(now-created)/float64(b.N), "goroutines/op")
+		} else {
+			b.ReportMetric(float64(peak-base), "extra-goroutines")
+		}
+	}
+
+	b.Run("cancelled", func(b *testing.B) {
+		c := New(&sync.Mutex{})
+		bench(b, func() error {
+			c.L.Lock()
+			defer c.L.Unlock()
+			_, err := c.WaitWithContext(cancelled)
+			return err
+		})
+	})
+	b.Run("parked", func(b *testing.B) {
+		bench(b, func() error {
+			l := &cancelOnUnlock{Context: context.Background(), done: make(chan struct{})}
+			c := New(l)
+			l.Mutex.Lock()
+			defer l.Mutex.Unlock()
+			_, err := c.WaitWithContext(l)
+			return err
+		})
+	})
+}
+
+func TestBroadcastAndSet(t *testing.T) {
+	var m sync.Mutex
+	c := New(&m)
+	ready := false
+	n := 3
+	results := make(chan bool, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			m.Lock()
+			c.Wait()
+			// The state is updated before any waiter runs again.
+			results <- ready
+			m.Unlock()
+		}()
+	}
+	waitParked(c, n)
+	woken := c.BroadcastAndSet(func() {
+		if m.TryLock() {
+			t.Error("set must run with locker held")
+			m.Unlock()
+		}
+		ready = true
+	})
+	if woken != n {
+		t.Fatalf("want %d, got %d", n, woken)
+	}
+	for i := 0; i < n; i++ {
+		if !<-results {
+			t.Fatal("waiter woke before the state was set")
+		}
+	}
+}
+
+func TestRWCondBroadcastAndSet(t *testing.T) {
+	c := NewRW(&sync.RWMutex{})
+	epoch := 0
+	done := make(chan bool)
+	go func() {
+		c.L.RLock()
+		done <- c.WaitFor(func() bool { return epoch == 1 })
+		c.L.RUnlock()
+	}()
+	waitParked(c, 1)
+	c.BroadcastAndSet(func() {
+		if c.L.TryRLock() {
+			t.Error("set must run with the write lock held")
+			c.L.RUnlock()
+		}
+		epoch++
+	})
+	if !<-done {
+		t.Fatal("want true")
+	}
+}
+
+func TestWithSpuriousWakeups(t *testing.T) {
+	c := New(&sync.Mutex{}, WithSpuriousWakeups(1))
+	c.L.Lock()
+	if !c.Wait() {
+		t.Fatal("want spurious wake to return true")
+	}
+	if ok, err := c.WaitWithContext(context.Background()); !ok || err != nil {
+		t.Fatalf("want true and nil, got %v and %v", ok, err)
+	}
+	c.L.Unlock()
+	if s := c.Stats(); s.Signalled != 0 || s.Broadcasts != 0 {
+		t.Fatalf("want no signals, got %+v", s)
+	}
+	c.Close()
+	c.L.Lock()
+	defer c.L.Unlock()
+	if c.Wait() {
+		t.Fatal("want false on closed Cond")
+	}
+}
+
+func TestWaitLastOut(t *testing.T) {
+	c := New(&sync.Mutex{})
+	n := 5
+	var last atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.L.Lock()
+			defer c.L.Unlock()
+			woken, wasLast := c.WaitLastOut()
+			if !woken {
+				t.Error("want true")
+			}
+			if wasLast {
+				last.Add(1)
+			}
+		}()
+	}
+	waitParked(c, n)
+	c.Broadcast()
+	wg.Wait()
+	if last.Load() != 1 {
+		t.Fatalf("want exactly one last waiter, got %d", last.Load())
+	}
+
+	// A single waiter is always the last one.
+	parked := make(chan struct{})
+	done := make(chan bool)
+	go func() {
+		c.L.Lock()
+		close(parked)
+		_, wasLast := c.WaitLastOut()
+		c.L.Unlock()
+		done <- wasLast
+	}()
+	<-parked
+	c.L.Lock()
+	c.L.Unlock()
+	c.Signal(1)
+	if !<-done {
+		t.Fatal("want wasLast for a single waiter")
+	}
+
+	// Spurious wakes do not park, so they are neither last nor counted.
+	c = New(&sync.Mutex{}, WithSpuriousWakeups(1))
+	c.L.Lock()
+	defer c.L.Unlock()
+	if woken, wasLast := c.WaitLastOut(); !woken || wasLast {
+		t.Fatalf("spurious wake: want true and false, got %v and %v", woken, wasLast)
+	}
+}
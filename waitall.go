@@ -0,0 +1,39 @@
+package cond
+
+import (
+	"context"
+	"sync"
+)
+
+// nopLocker is a locker for waits, which do not guard any state.
+type nopLocker struct{}
+
+func (nopLocker) Lock()   {}
+func (nopLocker) Unlock() {}
+
+// WaitAll blocks until every cond has been signalled or broadcast at least once, or closed, since WaitAll was called,
+// and returns nil. If ctx is cancelled first, it returns ctx.Err(), and if [WaitLimiter] rejects a wait, [ErrWaitLimit].
+// Each cond is waited on independently by its own goroutine without its locker, so the waits are counted by WaitCount
+// and may take Signal wakes like any other waiter. All waits are finished (and uncounted) before WaitAll returns.
+// The signal sequence of every cond is read before WaitAll starts waiting, so a signal sent after WaitAll was called
+// is observed even if the goroutine has not parked yet.
+func WaitAll(ctx context.Context, conds ...*Cond) error {
+	var wg sync.WaitGroup
+	var once sync.Once
+	var err error
+	for _, c := range conds {
+		if c.IsClosed() {
+			continue
+		}
+		seq := c.Seq()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, werr := c.waitSeqContext(nopLocker{}, ctx, seq); werr != nil {
+				once.Do(func() { err = werr })
+			}
+		}()
+	}
+	wg.Wait()
+	return err
+}
@@ -0,0 +1,56 @@
+package cond_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWaitAll(t *testing.T) {
+	a, b, c := New(&sync.Mutex{}), New(&sync.Mutex{}), New(&sync.Mutex{})
+	done := make(chan error)
+	go func() {
+		done <- WaitAll(context.Background(), a, b, c)
+	}()
+	waitParked(a, 1)
+	waitParked(b, 1)
+	waitParked(c, 1)
+
+	// Staggered signals: WaitAll returns only after the last one.
+	a.Signal(1)
+	waitParked(a, 0)
+	b.Broadcast()
+	waitParked(b, 0)
+	select {
+	case err := <-done:
+		t.Fatalf("returned before all conds were signalled: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	c.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+}
+
+func TestWaitAllCancelled(t *testing.T) {
+	a, b := New(&sync.Mutex{}), New(&sync.Mutex{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- WaitAll(ctx, a, b)
+	}()
+	waitParked(a, 1)
+	waitParked(b, 1)
+	a.Signal(1)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+	// All waits are deregistered.
+	if a.WaitCount() != 0 || b.WaitCount() != 0 {
+		t.Fatal("want no waiters after WaitAll returned")
+	}
+}
@@ -0,0 +1,106 @@
+package cond_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nursik/go-cond"
+)
+
+func TestWaitCountBucketed(t *testing.T) {
+	c := New(&sync.Mutex{})
+	defer c.Close()
+	buckets := []int{0, 2, 10}
+	want := map[int]int{0: 0, 1: 1, 2: 1, 3: 2, 10: 2, 11: 3}
+	parked := 0
+	for _, n := range []int{0, 1, 2, 3, 10, 11} {
+		for ; parked < n; parked++ {
+			go func() {
+				c.L.Lock()
+				c.Wait()
+				c.L.Unlock()
+			}()
+		}
+		waitParked(c, n)
+		if got := c.WaitCountBucketed(buckets); got != want[n] {
+			t.Fatalf("%d waiters: want bucket %d, got %d", n, want[n], got)
+		}
+	}
+}
+
+func TestWaitCountNeverNegative(t *testing.T) {
+	c := New(&sync.Mutex{})
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), time.Microsecond)
+				c.L.Lock()
+				c.WaitWithContext(ctx)
+				c.L.Unlock()
+				cancel()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				c.Signal(1)
+				c.Broadcast()
+			}
+		}
+	}()
+
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if n := c.WaitCount(); n < 0 {
+			close(stop)
+			t.Fatalf("WaitCount is negative: %d", n)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	if n := c.WaitCount(); n != 0 {
+		t.Fatalf("want 0 waiters, got %d", n)
+	}
+}
+
+func TestWaitCountCloseCancelRace(t *testing.T) {
+	for iter := 0; iter < 10; iter++ {
+		c := New(&sync.Mutex{})
+		n := 2000
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.L.Lock()
+				c.WaitWithContext(ctx)
+				c.L.Unlock()
+			}()
+		}
+		waitParked(c, n/2)
+		go cancel()
+		c.Close()
+		wg.Wait()
+		if got := c.WaitCount(); got != 0 {
+			t.Fatalf("want 0 waiters, got %d", got)
+		}
+	}
+}